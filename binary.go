@@ -0,0 +1,179 @@
+package fiber_errhandler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/gofiber/fiber"
+	"math"
+	"sort"
+)
+
+// Content types of the binary error renderers
+const (
+	MIMEApplicationMsgpack = "application/msgpack"
+	MIMEApplicationCBOR    = "application/cbor"
+)
+
+// Send error message as MessagePack, with the same structure as JSON
+func handleMsgpack(c *fiber.Ctx, cfg Config, args ...interface{}) {
+	sendBinary(c, cfg, MIMEApplicationMsgpack, encodeMsgpack, args...)
+}
+
+// Send error message as CBOR, with the same structure as JSON
+func handleCBOR(c *fiber.Ctx, cfg Config, args ...interface{}) {
+	sendBinary(c, cfg, MIMEApplicationCBOR, encodeCBOR, args...)
+}
+
+func sendBinary(c *fiber.Ctx, cfg Config, contentType string, encode func(*bytes.Buffer, interface{}), args ...interface{}) {
+	httpErr := getHTTPError(args...)
+	c.Status(httpErr.StatusCode())
+
+	// go through JSON so the data is encoded exactly as the JSON renderer would, e.g. honouring json tags
+	raw, err := json.Marshal(errorBody(c, cfg, httpErr))
+	if err != nil {
+		c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+		return
+	}
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+		return
+	}
+
+	var buf bytes.Buffer
+	encode(&buf, v)
+	c.Set(fiber.HeaderContentType, contentType)
+	c.SendBytes(buf.Bytes())
+}
+
+// Sorted keys of a decoded JSON object, so the output is deterministic
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Encode a decoded JSON value as MessagePack
+func encodeMsgpack(buf *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			if n >= 0 && n < 128 {
+				buf.WriteByte(byte(n))
+			} else if n < 0 && n >= -32 {
+				buf.WriteByte(byte(int8(n)))
+			} else {
+				buf.WriteByte(0xd3)
+				binary.Write(buf, binary.BigEndian, n)
+			}
+			return
+		}
+		f, _ := v.Float64()
+		buf.WriteByte(0xcb)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(f))
+	case string:
+		msgpackHeader(buf, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		buf.WriteString(v)
+	case []interface{}:
+		msgpackHeader(buf, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, e := range v {
+			encodeMsgpack(buf, e)
+		}
+	case map[string]interface{}:
+		msgpackHeader(buf, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		for _, k := range sortedKeys(v) {
+			encodeMsgpack(buf, k)
+			encodeMsgpack(buf, v[k])
+		}
+	}
+}
+
+// Write a MessagePack length header, `b8` is 0 for types without an 8 bit length form
+func msgpackHeader(buf *bytes.Buffer, n int, fix byte, fixMax int, b8, b16, b32 byte) {
+	if n < fixMax {
+		buf.WriteByte(fix | byte(n))
+	} else if n < 256 && b8 != 0 {
+		buf.WriteByte(b8)
+		buf.WriteByte(byte(n))
+	} else if n < 65536 {
+		buf.WriteByte(b16)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	} else {
+		buf.WriteByte(b32)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+// Encode a decoded JSON value as CBOR
+func encodeCBOR(buf *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xf6)
+	case bool:
+		if v {
+			buf.WriteByte(0xf5)
+		} else {
+			buf.WriteByte(0xf4)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			if n >= 0 {
+				cborHeader(buf, 0, uint64(n))
+			} else {
+				cborHeader(buf, 1, uint64(-1-n))
+			}
+			return
+		}
+		f, _ := v.Float64()
+		buf.WriteByte(0xfb)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(f))
+	case string:
+		cborHeader(buf, 3, uint64(len(v)))
+		buf.WriteString(v)
+	case []interface{}:
+		cborHeader(buf, 4, uint64(len(v)))
+		for _, e := range v {
+			encodeCBOR(buf, e)
+		}
+	case map[string]interface{}:
+		cborHeader(buf, 5, uint64(len(v)))
+		for _, k := range sortedKeys(v) {
+			encodeCBOR(buf, k)
+			encodeCBOR(buf, v[k])
+		}
+	}
+}
+
+// Write a CBOR initial byte with the major type and argument
+func cborHeader(buf *bytes.Buffer, major byte, n uint64) {
+	major <<= 5
+	if n < 24 {
+		buf.WriteByte(major | byte(n))
+	} else if n <= math.MaxUint8 {
+		buf.WriteByte(major | 24)
+		buf.WriteByte(byte(n))
+	} else if n <= math.MaxUint16 {
+		buf.WriteByte(major | 25)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	} else if n <= math.MaxUint32 {
+		buf.WriteByte(major | 26)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	} else {
+		buf.WriteByte(major | 27)
+		binary.Write(buf, binary.BigEndian, n)
+	}
+}
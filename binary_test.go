@@ -0,0 +1,104 @@
+package fiber_errhandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func decodeJSON(t *testing.T, s string) interface{} {
+	var v interface{}
+	d := json.NewDecoder(strings.NewReader(s))
+	d.UseNumber()
+	assert.NoError(t, d.Decode(&v))
+	return v
+}
+
+func TestEncodeMsgpack(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected []byte
+	}{
+		{`null`, []byte{0xc0}},
+		{`true`, []byte{0xc3}},
+		{`5`, []byte{0x05}},
+		{`-5`, []byte{0xfb}},
+		{`404`, []byte{0xd3, 0, 0, 0, 0, 0, 0, 0x01, 0x94}},
+		{`1.5`, []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{`"abc"`, []byte{0xa3, 'a', 'b', 'c'}},
+		{`[1,2]`, []byte{0x92, 0x01, 0x02}},
+		{`{"b":1,"a":null}`, []byte{0x82, 0xa1, 'a', 0xc0, 0xa1, 'b', 0x01}},
+	} {
+		var buf bytes.Buffer
+		encodeMsgpack(&buf, decodeJSON(t, tc.value))
+		assert.Equal(t, tc.expected, buf.Bytes(), tc.value)
+	}
+
+	var buf bytes.Buffer
+	encodeMsgpack(&buf, strings.Repeat("x", 40))
+	assert.Equal(t, []byte{0xd9, 40}, buf.Bytes()[:2])
+}
+
+func TestEncodeCBOR(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected []byte
+	}{
+		{`null`, []byte{0xf6}},
+		{`false`, []byte{0xf4}},
+		{`10`, []byte{0x0a}},
+		{`-10`, []byte{0x29}},
+		{`404`, []byte{0x19, 0x01, 0x94}},
+		{`1.5`, []byte{0xfb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{`"abc"`, []byte{0x63, 'a', 'b', 'c'}},
+		{`[1,2]`, []byte{0x82, 0x01, 0x02}},
+		{`{"b":1,"a":null}`, []byte{0xa2, 0x61, 'a', 0xf6, 0x61, 'b', 0x01}},
+	} {
+		var buf bytes.Buffer
+		encodeCBOR(&buf, decodeJSON(t, tc.value))
+		assert.Equal(t, tc.expected, buf.Bytes(), tc.value)
+	}
+}
+
+func TestErrHandler_binary(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(Config{
+		FormatQuery: "format",
+		Formats:     []string{"msgpack", "cbor"},
+	}))
+	app.Get("/", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusNotFound, "Not found", nil).WithCode("NF"))
+	})
+
+	msgpack := []byte{0x82, 0xa4, 'c', 'o', 'd', 'e', 0xa2, 'N', 'F', 0xa7, 'm', 'e', 's', 's', 'a', 'g', 'e', 0xa9, 'N', 'o', 't', ' ', 'f', 'o', 'u', 'n', 'd'}
+	cbor := []byte{0xa2, 0x64, 'c', 'o', 'd', 'e', 0x62, 'N', 'F', 0x67, 'm', 'e', 's', 's', 'a', 'g', 'e', 0x69, 'N', 'o', 't', ' ', 'f', 'o', 'u', 'n', 'd'}
+
+	for _, tc := range []struct {
+		target      string
+		accept      string
+		contentType string
+		expected    []byte
+	}{
+		{"/", "application/msgpack", MIMEApplicationMsgpack, msgpack},
+		{"/", "application/x-msgpack, application/json", MIMEApplicationMsgpack, msgpack},
+		{"/", "application/cbor", MIMEApplicationCBOR, cbor},
+		{"/?format=cbor", "application/json", MIMEApplicationCBOR, cbor},
+		{"/?format=msgpack", "", MIMEApplicationMsgpack, msgpack},
+	} {
+		req := httptest.NewRequest("GET", tc.target, nil)
+		req.Header.Set("Accept", tc.accept)
+		if resp, err := app.Test(req); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+			assert.Equal(t, tc.contentType, resp.Header.Get("Content-Type"))
+			body, _ := ioutil.ReadAll(resp.Body)
+			assert.Equal(t, tc.expected, body)
+		}
+	}
+}
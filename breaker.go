@@ -0,0 +1,241 @@
+package fiber_errhandler
+
+import (
+	"errors"
+	"github.com/gofiber/fiber"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PanicBreakerConfig ...
+type PanicBreakerConfig struct {
+	// Threshold is the number of panics of a route within Window which trips the breaker
+	// Optional. Default: 10
+	Threshold int
+	// Window is the period panics are counted over
+	// Optional. Default: 1 minute
+	Window time.Duration
+	// Cooldown is how long a tripped route responds with 503 before its handler is called again
+	// Optional. Default: 30 seconds
+	Cooldown time.Duration
+	// Output is a writer where trips and resets are logged
+	// Default: os.Stderr
+	Output io.Writer
+	// Reporting receives a report when the breaker of a route trips or resets
+	// Optional. Default: nil
+	Reporting *Reporting
+}
+
+type breakerRoute struct {
+	method    string
+	route     string
+	panics    int
+	start     time.Time
+	openUntil time.Time
+}
+
+// PanicBreaker counts panics per route and trips when a route keeps panicking,
+// the route then responds with a fast 503 error for the cooldown.
+// Routes are protected by mounting Guard, the 503 is rendered by the middleware.
+type PanicBreaker struct {
+	cfg PanicBreakerConfig
+
+	// number of tripped routes, so requests skip the lock while no route is tripped
+	open int32
+
+	mu     sync.Mutex
+	routes map[string]*breakerRoute
+}
+
+// Locals key holding the guards the request went through
+const localsBreakerGuards = "fiber-errhandler.breaker-guards"
+
+// Locals key holding the error of a tripped guard, rendered by the middleware
+const localsBreakerErr = "fiber-errhandler.breaker-err"
+
+type breakerGuard struct {
+	breaker *PanicBreaker
+	route   string
+}
+
+// NewPanicBreaker ...
+func NewPanicBreaker(config ...PanicBreakerConfig) *PanicBreaker {
+	var cfg PanicBreakerConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = 10
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	if cfg.Output == nil {
+		cfg.Output = os.Stderr
+	}
+	return &PanicBreaker{
+		cfg:    cfg,
+		routes: make(map[string]*breakerRoute),
+	}
+}
+
+// Tripped returns the tripped routes as `METHOD /route`, sorted
+func (b *PanicBreaker) Tripped() []string {
+	now := time.Now()
+	var tripped []string
+	b.mu.Lock()
+	for key, r := range b.routes {
+		if now.Before(r.openUntil) {
+			tripped = append(tripped, key)
+		}
+	}
+	b.mu.Unlock()
+	sort.Strings(tripped)
+	return tripped
+}
+
+// Guard returns a handler which counts the panics of the route or group it is mounted on and responds
+// with a fast 503 error while its breaker is tripped, e.g.
+//
+//	app.Get("/users/:id", breaker.Guard(), handler)
+//	api := app.Group("/api", breaker.Guard())
+//
+// Routes are told apart by the request method and the path the guard is mounted on, as matched by fiber,
+// so a guard mounted on a group trips for the whole group.
+func (b *PanicBreaker) Guard() func(*fiber.Ctx) {
+	return func(c *fiber.Ctx) {
+		route := c.Route().Path
+		if remaining, open := b.tripped(c, route); open {
+			c.Locals(localsBreakerErr, b.err(remaining))
+			return
+		}
+		guards, _ := c.Locals(localsBreakerGuards).([]breakerGuard)
+		c.Locals(localsBreakerGuards, append(guards[:len(guards):len(guards)], breakerGuard{breaker: b, route: route}))
+		c.Next()
+	}
+}
+
+// Count a panic for every guard the request went through
+func recordPanic(c *fiber.Ctx) {
+	guards, _ := c.Locals(localsBreakerGuards).([]breakerGuard)
+	for _, g := range guards {
+		g.breaker.record(c, g.route)
+	}
+}
+
+// Get the error of the guard which short-circuited the request, if any
+func breakerErr(c *fiber.Ctx) error {
+	err, _ := c.Locals(localsBreakerErr).(error)
+	return err
+}
+
+// Count a panic of the route, tripping the breaker once the threshold is reached
+func (b *PanicBreaker) record(c *fiber.Ctx, route string) {
+	method := copyString(c.Method())
+	key := method + " " + route
+	now := time.Now()
+
+	b.mu.Lock()
+	r, ok := b.routes[key]
+	if !ok {
+		r = &breakerRoute{method: method, route: route}
+		b.routes[key] = r
+	}
+	// panics of requests started before the trip
+	if !r.openUntil.IsZero() {
+		b.mu.Unlock()
+		return
+	}
+	if now.Sub(r.start) > b.cfg.Window {
+		r.start = now
+		r.panics = 0
+	}
+	r.panics++
+	trip := r.panics >= b.cfg.Threshold
+	if trip {
+		r.openUntil = now.Add(b.cfg.Cooldown)
+		atomic.AddInt32(&b.open, 1)
+	}
+	state := *r
+	b.mu.Unlock()
+
+	if trip {
+		b.emit(c, state, "tripped", "panic breaker tripped for "+key+" after "+strconv.Itoa(state.panics)+
+			" panics, responding 503 for "+b.cfg.Cooldown.String())
+	}
+}
+
+// Report whether the route is tripped for the method of the request, with the remaining cooldown.
+// The route is reset once its cooldown is over.
+func (b *PanicBreaker) tripped(c *fiber.Ctx, route string) (time.Duration, bool) {
+	if atomic.LoadInt32(&b.open) == 0 {
+		return 0, false
+	}
+	now := time.Now()
+
+	b.mu.Lock()
+	r, ok := b.routes[c.Method()+" "+route]
+	if !ok || r.openUntil.IsZero() {
+		b.mu.Unlock()
+		return 0, false
+	}
+	if now.Before(r.openUntil) {
+		remaining := r.openUntil.Sub(now)
+		b.mu.Unlock()
+		return remaining, true
+	}
+	r.openUntil = time.Time{}
+	r.panics = 0
+	atomic.AddInt32(&b.open, -1)
+	state := *r
+	b.mu.Unlock()
+
+	b.emit(c, state, "reset", "panic breaker reset for "+state.method+" "+state.route)
+	return 0, false
+}
+
+// Error sent while the route is tripped
+func (b *PanicBreaker) err(remaining time.Duration) HTTPError {
+	return NewHttpError(fiber.StatusServiceUnavailable, http.StatusText(fiber.StatusServiceUnavailable), nil).
+		WithCode("PANIC_BREAKER").
+		WithRetry(remaining)
+}
+
+// Log the trip or reset and send it to Reporting
+func (b *PanicBreaker) emit(c *fiber.Ctx, r breakerRoute, state string, msg string) {
+	b.cfg.Output.Write([]byte(msg + "\n"))
+	if b.cfg.Reporting == nil {
+		return
+	}
+
+	err := errors.New(msg)
+	b.cfg.Reporting.enqueue(&Report{
+		Event: Event{
+			Time:        time.Now(),
+			Err:         err,
+			StatusCode:  fiber.StatusServiceUnavailable,
+			Code:        "PANIC_BREAKER",
+			Method:      r.method,
+			Route:       r.route,
+			Path:        copyString(c.Path()),
+			RequestID:   copyString(requestID(c)),
+			Fingerprint: fingerprint(err, "PANIC_BREAKER", nil),
+		},
+		Request: ReportRequest{
+			Method: r.method,
+			URL:    copyString(c.BaseURL() + c.Path()),
+		},
+		Tags: map[string]string{
+			"breaker": state,
+		},
+	})
+}
@@ -0,0 +1,184 @@
+package fiber_errhandler
+
+import (
+	"bytes"
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type breakerReporter struct {
+	mu      sync.Mutex
+	reports []*Report
+}
+
+func (r *breakerReporter) Report(report *Report) {
+	r.mu.Lock()
+	r.reports = append(r.reports, report)
+	r.mu.Unlock()
+}
+
+func TestErrHandler_panic_breaker(t *testing.T) {
+	out := &bytes.Buffer{}
+	reporter := &breakerReporter{}
+	reporting := NewReporting(reporter)
+	breaker := NewPanicBreaker(PanicBreakerConfig{
+		Threshold: 3,
+		Cooldown:  50 * time.Millisecond,
+		Output:    out,
+		Reporting: reporting,
+	})
+
+	var calls int32
+	app := fiber.New()
+	app.Use(New())
+	app.Get("/users/:id", breaker.Guard(), func(c *fiber.Ctx) {
+		atomic.AddInt32(&calls, 1)
+		panic("boom")
+	})
+	app.Get("/ok", func(c *fiber.Ctx) {
+		c.SendString("OK")
+	})
+
+	get := func(target string) (int, string) {
+		resp, err := app.Test(httptest.NewRequest("GET", target, nil))
+		if !assert.NoError(t, err) {
+			return 0, ""
+		}
+		return resp.StatusCode, resp.Header.Get("Retry-After")
+	}
+
+	for i := 1; i <= 3; i++ {
+		status, _ := get("/users/1")
+		assert.Equal(t, fiber.StatusInternalServerError, status)
+	}
+	assert.Equal(t, []string{"GET /users/:id"}, breaker.Tripped())
+	assert.Contains(t, out.String(), "panic breaker tripped for GET /users/:id after 3 panics")
+
+	status, retryAfter := get("/users/2")
+	assert.Equal(t, fiber.StatusServiceUnavailable, status)
+	assert.Equal(t, "1", retryAfter)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	status, _ = get("/ok")
+	assert.Equal(t, fiber.StatusOK, status)
+
+	time.Sleep(60 * time.Millisecond)
+	status, _ = get("/users/3")
+	assert.Equal(t, fiber.StatusInternalServerError, status)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+	assert.Empty(t, breaker.Tripped())
+	assert.Contains(t, out.String(), "panic breaker reset for GET /users/:id")
+
+	reporting.Close()
+	var tags []string
+	for _, r := range reporter.reports {
+		if r.Tags != nil {
+			tags = append(tags, r.Tags["breaker"])
+			assert.Equal(t, "/users/:id", r.Route)
+			assert.Equal(t, "PANIC_BREAKER", r.Code)
+		}
+	}
+	assert.Equal(t, []string{"tripped", "reset"}, tags)
+}
+
+func TestPanicBreaker_concurrent(t *testing.T) {
+	breaker := NewPanicBreaker(PanicBreakerConfig{
+		Threshold: 50,
+		Cooldown:  time.Minute,
+		Output:    &bytes.Buffer{},
+	})
+	app := fiber.New()
+	app.Use(New())
+	app.Get("/", breaker.Guard(), func(c *fiber.Ctx) {
+		panic("boom")
+	})
+
+	app.Settings.DisableStartupMessage = true
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go app.Serve(ln)
+	defer app.Shutdown()
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	var wg sync.WaitGroup
+	var unavailable int32
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if resp, err := client.Get("http://" + ln.Addr().String() + "/"); err == nil {
+				resp.Body.Close()
+				if resp.StatusCode == fiber.StatusServiceUnavailable {
+					atomic.AddInt32(&unavailable, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, []string{"GET /"}, breaker.Tripped())
+	assert.True(t, unavailable <= 50)
+
+	// requests after the trip are short-circuited
+	for i := 0; i < 3; i++ {
+		if resp, err := client.Get("http://" + ln.Addr().String() + "/"); assert.NoError(t, err) {
+			resp.Body.Close()
+			assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+		}
+	}
+}
+
+func TestPanicBreaker_routes(t *testing.T) {
+	breaker := NewPanicBreaker(PanicBreakerConfig{
+		Threshold: 2,
+		Output:    &bytes.Buffer{},
+	})
+	app := fiber.New()
+	app.Use(New())
+	// routes matching the path of a tripped route are not affected
+	app.Get("/users/new", breaker.Guard(), func(c *fiber.Ctx) {
+		c.SendString("new")
+	})
+	app.Get("/users/:id", breaker.Guard(), func(c *fiber.Ctx) {
+		panic("boom")
+	})
+	// a guard mounted on a prefix trips for every path of the prefix
+	app.Use("/api", breaker.Guard(), func(c *fiber.Ctx) {
+		if c.Path() == "/api/panic" {
+			panic("boom")
+		}
+		c.Next()
+	})
+	app.Get("/api/x", func(c *fiber.Ctx) {
+		c.SendString("x")
+	})
+
+	get := func(target string) int {
+		resp, err := app.Test(httptest.NewRequest("GET", target, nil))
+		if !assert.NoError(t, err) {
+			return 0
+		}
+		return resp.StatusCode
+	}
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, fiber.StatusInternalServerError, get("/users/1"))
+	}
+	assert.Equal(t, []string{"GET /users/:id"}, breaker.Tripped())
+	assert.Equal(t, fiber.StatusServiceUnavailable, get("/users/2"))
+	assert.Equal(t, fiber.StatusOK, get("/users/new"))
+
+	assert.Equal(t, fiber.StatusOK, get("/api/x"))
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, fiber.StatusInternalServerError, get("/api/panic"))
+	}
+	assert.Equal(t, []string{"GET /api", "GET /users/:id"}, breaker.Tripped())
+	assert.Equal(t, fiber.StatusServiceUnavailable, get("/api/panic"))
+	assert.Equal(t, fiber.StatusServiceUnavailable, get("/api/x"))
+}
@@ -0,0 +1,25 @@
+package fiber_errhandler
+
+import (
+	"github.com/gofiber/fiber"
+)
+
+// Report whether the failed handler streams the response or hijacked the connection
+func responseStreamed(c *fiber.Ctx) bool {
+	return c.Fasthttp.Hijacked() || c.Fasthttp.Response.IsBodyStream()
+}
+
+// Report whether the failed handler already started the response,
+// i.e. wrote to the body, set a body stream or hijacked the connection.
+func responseStarted(c *fiber.Ctx) bool {
+	return responseStreamed(c) || len(c.Fasthttp.Response.Body()) > 0
+}
+
+// Safe mode for already started responses: the error is logged if `cfg.Log` is set and the connection is closed
+// once the response is sent, the error body is never mixed with what the handler wrote.
+func abortResponse(c *fiber.Ctx, cfg Config, e *Event) {
+	if cfg.Log {
+		writeLog(cfg, e, e.Err.Error()+" (response already started, fingerprint: "+e.Fingerprint+")\n")
+	}
+	c.Fasthttp.SetConnectionClose()
+}
@@ -3,6 +3,7 @@ package fiber_errhandler
 import (
 	"fmt"
 	"github.com/gofiber/fiber"
+	"time"
 )
 
 type HTTPError interface {
@@ -18,10 +19,38 @@ type HTTPError interface {
 	Error() string
 }
 
+// RPCCoder is implemented by errors which carry a JSON-RPC application error code
+type RPCCoder interface {
+	RPCCode() int
+}
+
+// Coder is implemented by errors which carry an application specific error code,
+// e.g. `USER_NOT_FOUND`.
+type Coder interface {
+	Code() string
+}
+
+// Retryable is implemented by errors which tell clients whether the request can be retried
+type Retryable interface {
+	// Whether the request can be retried
+	Retryable() bool
+	// Suggested delay before retrying, sent as `Retry-After`, zero if none
+	RetryAfter() time.Duration
+	// Note about retrying non-idempotent requests, e.g. `retry with the same Idempotency-Key`
+	IdempotencyNote() string
+}
+
 type httpError struct {
 	statusCode int
 	message string
 	data interface{}
+	code string
+	messageKey string
+	messageArgs map[string]interface{}
+	rpcCode int
+	retryable bool
+	retryAfter time.Duration
+	idempotencyNote string
 }
 
 func NewHttpError(statusCode int, message string, data interface{}) *httpError {
@@ -47,6 +76,74 @@ func (he *httpError) Data() interface{} {
 	return he.data
 }
 
+func (he *httpError) Code() string {
+	return he.code
+}
+
+// WithCode sets the application specific error code
+func (he *httpError) WithCode(code string) *httpError {
+	he.code = code
+	return he
+}
+
+func (he *httpError) MessageKey() string {
+	return he.messageKey
+}
+
+func (he *httpError) MessageArgs() map[string]interface{} {
+	return he.messageArgs
+}
+
+// WithMessageKey sets the key and arguments used to resolve a localised message from Config.Catalog,
+// Message is used when the key cannot be resolved
+func (he *httpError) WithMessageKey(key string, args map[string]interface{}) *httpError {
+	he.messageKey = key
+	he.messageArgs = args
+	return he
+}
+
+func (he *httpError) RPCCode() int {
+	return he.rpcCode
+}
+
+// WithRPCCode sets the JSON-RPC application error code, used instead of the code mapped from the status code
+func (he *httpError) WithRPCCode(code int) *httpError {
+	he.rpcCode = code
+	return he
+}
+
+func (he *httpError) Retryable() bool {
+	return he.retryable
+}
+
+func (he *httpError) RetryAfter() time.Duration {
+	return he.retryAfter
+}
+
+func (he *httpError) IdempotencyNote() string {
+	return he.idempotencyNote
+}
+
+// WithRetry marks the error as retryable after the suggested delay, zero if there is no suggestion
+func (he *httpError) WithRetry(after time.Duration) *httpError {
+	he.retryable = true
+	he.retryAfter = after
+	return he
+}
+
+// WithIdempotencyNote sets a note about retrying non-idempotent requests
+func (he *httpError) WithIdempotencyNote(note string) *httpError {
+	he.idempotencyNote = note
+	return he
+}
+
+// Copy of the error with another message
+func (he *httpError) withMessage(message string) HTTPError {
+	cp := *he
+	cp.message = message
+	return &cp
+}
+
 func (he *httpError) Error() string {
 	return fmt.Sprintf("statusCode: %d, message: %s", he.statusCode, he.message)
 }
@@ -0,0 +1,118 @@
+package fiber_errhandler
+
+import (
+	"errors"
+	"github.com/gofiber/fiber"
+	"runtime"
+	"time"
+)
+
+// Event describes an error handled by the middleware
+type Event struct {
+	// Time when the middleware started handling the error
+	Time time.Time
+	// The error, after transformers were applied
+	Err error
+	// Type name of the error, the type of the value for panics with a non-error value
+	Type string
+	// Status code of the error response, the status the error would have been rendered with
+	// when the error could not be rendered, e.g. Aborted or the response was already started
+	StatusCode int
+	// Application specific error code, see Coder
+	Code string
+	// HTTP method of the request
+	Method string
+	// Route path the request matched, e.g. `/users/:id`
+	Route string
+	// Path of the request
+	Path string
+	// Request ID, taken from the `X-Request-ID` response or request header
+	RequestID string
+	// Panic is true if the error was recovered from a panic
+	Panic bool
+	// Aborted is true if no response was rendered because the panic policy aborted the connection or panicked again
+	Aborted bool
+	// Stack of the panic, nil for errors passed to c.Next
+	Stack []uintptr
+	// Fingerprint groups identical failures, built from the error type, code, normalised message and top stack frames
+	Fingerprint string
+	// Time spent handling the error
+	Duration time.Duration
+}
+
+// Frames returns the frames of the panic stack, runtime frames are skipped
+func (e *Event) Frames() []runtime.Frame {
+	return stackFrames(e.Stack)
+}
+
+// Observer is notified of every error handled by the middleware, after the response is rendered.
+// Observe is called from fiber's worker goroutines, hence must be safe for concurrent use.
+type Observer interface {
+	Observe(c *fiber.Ctx, e *Event)
+}
+
+// Get the application specific error code of err, if any
+func errorCode(err error) string {
+	var coder Coder
+	if errors.As(err, &coder) {
+		return coder.Code()
+	}
+	return ""
+}
+
+// Get the message of err, HTTPError.Message if available
+func errorMessage(err error) string {
+	var he HTTPError
+	if errors.As(err, &he) {
+		return he.Message()
+	}
+	return err.Error()
+}
+
+// Strings returned by fiber.Ctx are only valid within the handler, copy them so events can be retained
+func copyString(s string) string {
+	return string(append([]byte(nil), s...))
+}
+
+// Get the request ID set by a request ID middleware or sent by the client
+func requestID(c *fiber.Ctx) string {
+	if id := c.Fasthttp.Response.Header.Peek(fiber.HeaderXRequestID); len(id) > 0 {
+		return string(id)
+	}
+	return c.Get(fiber.HeaderXRequestID)
+}
+
+func newEvent(c *fiber.Ctx, err error, stack []uintptr, start time.Time) *Event {
+	e := &Event{
+		Time:      start,
+		Err:       err,
+		Type:      errorType(err),
+		Code:      errorCode(err),
+		Method:    copyString(c.Method()),
+		Path:      copyString(c.Path()),
+		RequestID: copyString(requestID(c)),
+		Panic:     stack != nil,
+		Stack:     stack,
+	}
+	if route := c.Route(); route != nil {
+		e.Route = route.Path
+	}
+	e.Fingerprint = fingerprint(err, e.Code, stack)
+	return e
+}
+
+// Format the log line of the event
+func logLine(e *Event) string {
+	return e.Err.Error() + " (fingerprint: " + e.Fingerprint + ")\n"
+}
+
+// Complete the event once the response is rendered and notify the observers
+func notify(c *fiber.Ctx, cfg Config, e *Event) {
+	if e.StatusCode == 0 {
+		e.StatusCode = c.Fasthttp.Response.StatusCode()
+	}
+	e.Duration = time.Since(e.Time)
+	for _, o := range cfg.Observers {
+		o.Observe(c, e)
+	}
+}
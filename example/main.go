@@ -39,7 +39,10 @@ func main() {
 		},
 	}))
 
-	apiv1 := app.Group("/api")
+	// always respond with JSON under /api
+	apiv1 := app.Group("/api", errhandler.Override(func(cfg *errhandler.Config) {
+		cfg.ContentType = fiber.MIMEApplicationJSON
+	}))
 	apiv1.Get("/", func(c *fiber.Ctx) {
 		c.JSON(data)
 	})
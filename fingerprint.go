@@ -0,0 +1,153 @@
+package fiber_errhandler
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Variable parts of error messages, replaced so identical failures share a fingerprint
+var messageNormalizers = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`"[^"]*"|'[^']*'`), "<str>"},
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`\b(0x)?[0-9a-f]{8,}\b`), "<hex>"},
+	{regexp.MustCompile(`\d+`), "<n>"},
+}
+
+// Replace the variable parts of an error message, e.g. ids and numbers
+func normalizeMessage(msg string) string {
+	msg = strings.ToLower(msg)
+	for _, n := range messageNormalizers {
+		msg = n.re.ReplaceAllString(msg, n.repl)
+	}
+	return msg
+}
+
+// Build the fingerprint of an error from its type, code, normalised message and top stack frames
+func fingerprint(err error, code string, stack []uintptr) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n", errorType(err), code, normalizeMessage(errorMessage(err)))
+	// transient errors share their status and message, the cause tells them apart
+	var te *transientError
+	if errors.As(err, &te) {
+		fmt.Fprintf(h, "%s\n", normalizeMessage(te.cause.Error()))
+	}
+	frames := stackFrames(stack)
+	if len(frames) > fingerprintFrames {
+		frames = frames[:fingerprintFrames]
+	}
+	for _, f := range frames {
+		fmt.Fprintf(h, "%s:%d\n", f.Function, f.Line)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// FingerprintStats aggregates the errors sharing a fingerprint
+type FingerprintStats struct {
+	Fingerprint string    `json:"fingerprint"`
+	Count       uint64    `json:"count"`
+	FirstSeen   time.Time `json:"firstSeen"`
+	LastSeen    time.Time `json:"lastSeen"`
+	// Details of the last error
+	StatusCode int    `json:"status"`
+	Code       string `json:"code,omitempty"`
+	Method     string `json:"method"`
+	Route      string `json:"route"`
+	Message    string `json:"message"`
+	Panic      bool   `json:"panic"`
+}
+
+// Fingerprints aggregates count, first-seen and last-seen of handled errors per fingerprint.
+// Add it to `Config.Observers`, Fingerprints.Handler lists the aggregates as JSON.
+type Fingerprints struct {
+	max int
+
+	mu    sync.Mutex
+	stats map[string]*FingerprintStats
+}
+
+// NewFingerprints returns a Fingerprints keeping at most `max` fingerprints,
+// the least recently seen fingerprint is evicted when full.
+func NewFingerprints(max int) *Fingerprints {
+	if max <= 0 {
+		max = 1000
+	}
+	return &Fingerprints{
+		max:   max,
+		stats: make(map[string]*FingerprintStats),
+	}
+}
+
+// Observe implements Observer
+func (f *Fingerprints) Observe(c *fiber.Ctx, e *Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.stats[e.Fingerprint]
+	if !ok {
+		if len(f.stats) >= f.max {
+			f.evict()
+		}
+		s = &FingerprintStats{
+			Fingerprint: e.Fingerprint,
+			FirstSeen:   e.Time,
+		}
+		f.stats[e.Fingerprint] = s
+	}
+	s.Count++
+	s.LastSeen = e.Time
+	s.StatusCode = e.StatusCode
+	s.Code = e.Code
+	s.Method = e.Method
+	s.Route = e.Route
+	s.Message = errorMessage(e.Err)
+	s.Panic = e.Panic
+}
+
+// Remove the least recently seen fingerprint
+func (f *Fingerprints) evict() {
+	var oldest *FingerprintStats
+	for _, s := range f.stats {
+		if oldest == nil || s.LastSeen.Before(oldest.LastSeen) {
+			oldest = s
+		}
+	}
+	if oldest != nil {
+		delete(f.stats, oldest.Fingerprint)
+	}
+}
+
+// Stats returns the aggregates, most frequent first
+func (f *Fingerprints) Stats() []FingerprintStats {
+	f.mu.Lock()
+	stats := make([]FingerprintStats, 0, len(f.stats))
+	for _, s := range f.stats {
+		stats = append(stats, *s)
+	}
+	f.mu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Fingerprint < stats[j].Fingerprint
+	})
+	return stats
+}
+
+// Handler returns a handler listing the aggregates as JSON
+func (f *Fingerprints) Handler() func(*fiber.Ctx) {
+	return func(c *fiber.Ctx) {
+		c.JSON(f.Stats())
+	}
+}
@@ -0,0 +1,48 @@
+package fiber_errhandler
+
+import (
+	"errors"
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNormalizeMessage(t *testing.T) {
+	assert.Equal(t, "user <n> not found", normalizeMessage("User 42 not found"))
+	assert.Equal(t, "order <uuid> failed: <str>", normalizeMessage(`order 1b4e28ba-2fa1-11d2-883f-0016d3cca427 failed: "timeout"`))
+	assert.Equal(t, "object <hex> missing", normalizeMessage("object 5f2b8c9e0a1d missing"))
+}
+
+func TestFingerprints(t *testing.T) {
+	fingerprints := NewFingerprints(10)
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Observers: []Observer{fingerprints},
+	}))
+	app.Get("/fingerprints", fingerprints.Handler())
+	app.Get("/users/:id", func(c *fiber.Ctx) {
+		c.Next(errors.New("user " + c.Params("id") + " not found"))
+	})
+	app.Get("/panic", func(c *fiber.Ctx) {
+		panic("i'm panic")
+	})
+
+	for _, path := range []string{"/users/1", "/users/2", "/users/3", "/panic"} {
+		if _, err := app.Test(httptest.NewRequest("GET", path, nil)); err != nil {
+			assert.NoError(t, err)
+		}
+	}
+
+	stats := fingerprints.Stats()
+	if assert.Len(t, stats, 2) {
+		assert.Equal(t, uint64(3), stats[0].Count)
+		assert.Equal(t, "user 3 not found", stats[0].Message)
+		assert.Equal(t, "/users/:id", stats[0].Route)
+		assert.False(t, stats[0].FirstSeen.After(stats[0].LastSeen))
+		assert.Equal(t, uint64(1), stats[1].Count)
+		assert.True(t, stats[1].Panic)
+		assert.NotEqual(t, stats[0].Fingerprint, stats[1].Fingerprint)
+	}
+}
@@ -0,0 +1,49 @@
+package fiber_errhandler
+
+import (
+	"github.com/gofiber/fiber"
+	"path"
+	"strings"
+)
+
+// Content types of the formats which can be requested with `Config.FormatQuery` or `Config.FormatExtension`
+var formatTypes = map[string]string{
+	"json":    fiber.MIMEApplicationJSON,
+	"jsonapi": MIMEApplicationJSONAPI,
+	"html":    fiber.MIMETextHTML,
+	"htm":     fiber.MIMETextHTML,
+	"txt":     fiber.MIMETextPlain,
+	"text":    fiber.MIMETextPlain,
+	"msgpack": MIMEApplicationMsgpack,
+	"cbor":    MIMEApplicationCBOR,
+}
+
+// Formats allowed when `Config.Formats` is not set
+var defaultFormats = []string{"json", "html", "txt"}
+
+// Get the content type requested through the query parameter or the path extension.
+// Return empty string if none is requested or the format is not allowed.
+func getRequestedContentType(c *fiber.Ctx, cfg Config) string {
+	format := ""
+	if cfg.FormatQuery != "" {
+		format = c.Query(cfg.FormatQuery)
+	}
+	if format == "" && cfg.FormatExtension {
+		format = strings.TrimPrefix(path.Ext(c.Path()), ".")
+	}
+	if format == "" {
+		return ""
+	}
+	format = strings.ToLower(format)
+
+	formats := cfg.Formats
+	if formats == nil {
+		formats = defaultFormats
+	}
+	for _, f := range formats {
+		if strings.ToLower(f) == format {
+			return formatTypes[format]
+		}
+	}
+	return ""
+}
@@ -1,11 +1,22 @@
 module github.com/hendratommy/fiber-errhandler
 
-go 1.14
+go 1.17
 
 require (
 	github.com/gofiber/fiber v1.10.1
-	github.com/gofiber/recover v0.1.0 // indirect
 	github.com/gofiber/template v1.3.1
-	github.com/klauspost/compress v1.10.6 // indirect
 	github.com/stretchr/testify v1.5.1
 )
+
+require (
+	github.com/andybalholm/brotli v1.0.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/gofiber/recover v0.1.0 // indirect
+	github.com/gofiber/utils v0.0.3 // indirect
+	github.com/gorilla/schema v1.1.0 // indirect
+	github.com/klauspost/compress v1.10.6 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasthttp v1.13.1 // indirect
+	gopkg.in/yaml.v2 v2.3.0 // indirect
+)
@@ -0,0 +1,118 @@
+package fiber_errhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gofiber/fiber"
+	"net/http"
+	"strings"
+)
+
+// MIMEApplicationGraphQLResponse is the media type of GraphQL responses defined by the GraphQL-over-HTTP spec
+const MIMEApplicationGraphQLResponse = "application/graphql-response+json"
+
+// GraphQLLocation is a location in the GraphQL document
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLErrorer is implemented by errors carrying the path and locations of a GraphQL failure
+type GraphQLErrorer interface {
+	// Path of the response field which failed, e.g. ["user", "friends", 0, "name"]
+	Path() []interface{}
+	Locations() []GraphQLLocation
+}
+
+type graphQLError struct {
+	Message    string                 `json:"message"`
+	Locations  []GraphQLLocation      `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Name a status code the GraphQL way, e.g. `NOT_FOUND`
+func statusCodeName(status int) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.ReplaceAll(http.StatusText(status), " ", "_"), "-", "_"))
+}
+
+// Decide whether the client accepts `application/graphql-response+json` over `application/json`
+func acceptsGraphQLResponse(c *fiber.Ctx) bool {
+	for _, val := range strings.Split(strings.ToLower(c.Get(fiber.HeaderAccept)), ",") {
+		if i := strings.IndexByte(val, ';'); i != -1 {
+			val = val[:i]
+		}
+		switch strings.TrimSpace(val) {
+		case MIMEApplicationGraphQLResponse:
+			return true
+		case fiber.MIMEApplicationJSON, "*/*":
+			return false
+		}
+	}
+	return false
+}
+
+// Send error as GraphQL response `{"errors":[...],"data":null}`.
+// With `application/graphql-response+json` the status code of the error is used, 4xx errors being request errors,
+// and `data` is omitted as a response with `data` must be 2xx.
+// With `application/json` the status is always 200, as required by the GraphQL-over-HTTP spec.
+func handleGraphQL(c *fiber.Ctx, cfg Config, args ...interface{}) {
+	httpErr := getHTTPError(args...)
+	status := httpErr.StatusCode()
+
+	code := errorCode(httpErr)
+	if code == "" {
+		code = statusCodeName(status)
+	}
+	base := graphQLError{
+		Message:    httpErr.Message(),
+		Extensions: map[string]interface{}{"code": code},
+	}
+	var ge GraphQLErrorer
+	if errors.As(httpErr, &ge) {
+		base.Path = ge.Path()
+		base.Locations = ge.Locations()
+	}
+	if traceID := getTraceID(c, cfg); traceID != "" {
+		base.Extensions["traceId"] = traceID
+	}
+
+	var errs []graphQLError
+	var fe FieldErrorer
+	if errors.As(httpErr, &fe) && len(fe.FieldErrors()) > 0 {
+		for _, f := range fe.FieldErrors() {
+			e := base
+			e.Message = f.Message
+			e.Extensions = map[string]interface{}{"field": f.Field}
+			for k, v := range base.Extensions {
+				e.Extensions[k] = v
+			}
+			if f.Code != "" {
+				e.Extensions["code"] = f.Code
+			}
+			errs = append(errs, e)
+		}
+	} else {
+		errs = append(errs, base)
+	}
+
+	response := fiber.Map{"errors": errs}
+	if acceptsGraphQLResponse(c) {
+		if status < 400 {
+			status = fiber.StatusInternalServerError
+		}
+		c.Set(fiber.HeaderContentType, MIMEApplicationGraphQLResponse)
+	} else {
+		response["data"] = nil
+		status = fiber.StatusOK
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		c.SendStatus(fiber.StatusInternalServerError)
+		return
+	}
+	c.Status(status)
+	c.SendBytes(body)
+}
@@ -0,0 +1,97 @@
+package fiber_errhandler
+
+import (
+	"encoding/json"
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+type resolverError struct {
+	*httpError
+}
+
+func (re resolverError) Path() []interface{} {
+	return []interface{}{"user", "friends", 0}
+}
+
+func (re resolverError) Locations() []GraphQLLocation {
+	return []GraphQLLocation{{Line: 2, Column: 3}}
+}
+
+func TestErrHandler_graphql(t *testing.T) {
+	app := fiber.New()
+	app.Use(New())
+	app.Post("/graphql", Override(func(cfg *Config) {
+		cfg.GraphQL = true
+	}), func(c *fiber.Ctx) {
+		if c.Query("panic") != "" {
+			panic("i'm panic")
+		}
+		c.Next(resolverError{NewHttpError(fiber.StatusForbidden, "Cannot access friends", nil)})
+	})
+
+	req := httptest.NewRequest("POST", "/graphql", nil)
+	req.Header.Set("Accept", "application/json")
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get(fiber.HeaderContentType))
+		b := make(map[string]interface{})
+		if err := json.NewDecoder(resp.Body).Decode(&b); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, map[string]interface{}{
+				"errors": []interface{}{
+					map[string]interface{}{
+						"message":    "Cannot access friends",
+						"path":       []interface{}{"user", "friends", float64(0)},
+						"locations":  []interface{}{map[string]interface{}{"line": float64(2), "column": float64(3)}},
+						"extensions": map[string]interface{}{"code": "FORBIDDEN"},
+					},
+				},
+				"data": nil,
+			}, b)
+		}
+	}
+
+	req = httptest.NewRequest("POST", "/graphql", nil)
+	req.Header.Set("Accept", MIMEApplicationGraphQLResponse+", application/json;q=0.9")
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+		assert.Equal(t, MIMEApplicationGraphQLResponse, resp.Header.Get(fiber.HeaderContentType))
+		b := make(map[string]interface{})
+		if err := json.NewDecoder(resp.Body).Decode(&b); err != nil {
+			assert.NoError(t, err)
+		} else {
+			// request errors omit data
+			assert.NotContains(t, b, "data")
+			assert.Len(t, b["errors"], 1)
+		}
+	}
+
+	req = httptest.NewRequest("POST", "/graphql?panic=1", nil)
+	req.Header.Set("Accept", MIMEApplicationGraphQLResponse)
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+		b := make(map[string]interface{})
+		if err := json.NewDecoder(resp.Body).Decode(&b); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, map[string]interface{}{
+				"errors": []interface{}{
+					map[string]interface{}{
+						"message":    "i'm panic",
+						"extensions": map[string]interface{}{"code": "INTERNAL_SERVER_ERROR"},
+					},
+				},
+			}, b)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package fiber_errhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gofiber/fiber"
+	"strconv"
+	"time"
+)
+
+// GRPCCode is a canonical gRPC status code
+type GRPCCode int
+
+// Canonical gRPC status codes
+const (
+	GRPCOK GRPCCode = iota
+	GRPCCanceled
+	GRPCUnknown
+	GRPCInvalidArgument
+	GRPCDeadlineExceeded
+	GRPCNotFound
+	GRPCAlreadyExists
+	GRPCPermissionDenied
+	GRPCResourceExhausted
+	GRPCFailedPrecondition
+	GRPCAborted
+	GRPCOutOfRange
+	GRPCUnimplemented
+	GRPCInternal
+	GRPCUnavailable
+	GRPCDataLoss
+	GRPCUnauthenticated
+)
+
+var grpcCodeNames = []string{
+	"OK", "CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED", "NOT_FOUND", "ALREADY_EXISTS",
+	"PERMISSION_DENIED", "RESOURCE_EXHAUSTED", "FAILED_PRECONDITION", "ABORTED", "OUT_OF_RANGE",
+	"UNIMPLEMENTED", "INTERNAL", "UNAVAILABLE", "DATA_LOSS", "UNAUTHENTICATED",
+}
+
+var grpcCodeStatus = []int{
+	fiber.StatusOK, 499, fiber.StatusInternalServerError, fiber.StatusBadRequest, fiber.StatusGatewayTimeout,
+	fiber.StatusNotFound, fiber.StatusConflict, fiber.StatusForbidden, fiber.StatusTooManyRequests,
+	fiber.StatusBadRequest, fiber.StatusConflict, fiber.StatusBadRequest, fiber.StatusNotImplemented,
+	fiber.StatusInternalServerError, fiber.StatusServiceUnavailable, fiber.StatusInternalServerError,
+	fiber.StatusUnauthorized,
+}
+
+// String returns the canonical name of the code, e.g. `NOT_FOUND`
+func (code GRPCCode) String() string {
+	if code < 0 || int(code) >= len(grpcCodeNames) {
+		return "CODE(" + strconv.Itoa(int(code)) + ")"
+	}
+	return grpcCodeNames[code]
+}
+
+// HTTPStatus returns the HTTP status code the gRPC code maps to
+func (code GRPCCode) HTTPStatus() int {
+	if code < 0 || int(code) >= len(grpcCodeStatus) {
+		return fiber.StatusInternalServerError
+	}
+	return grpcCodeStatus[code]
+}
+
+// GRPCCodeFromStatus maps an HTTP status code to a gRPC code
+func GRPCCodeFromStatus(status int) GRPCCode {
+	switch status {
+	case fiber.StatusOK:
+		return GRPCOK
+	case fiber.StatusBadRequest, fiber.StatusUnprocessableEntity:
+		return GRPCInvalidArgument
+	case fiber.StatusUnauthorized:
+		return GRPCUnauthenticated
+	case fiber.StatusForbidden:
+		return GRPCPermissionDenied
+	case fiber.StatusNotFound:
+		return GRPCNotFound
+	case fiber.StatusConflict:
+		return GRPCAborted
+	case fiber.StatusPreconditionFailed:
+		return GRPCFailedPrecondition
+	case fiber.StatusRequestedRangeNotSatisfiable:
+		return GRPCOutOfRange
+	case fiber.StatusTooManyRequests:
+		return GRPCResourceExhausted
+	case 499:
+		return GRPCCanceled
+	case fiber.StatusNotImplemented:
+		return GRPCUnimplemented
+	case fiber.StatusServiceUnavailable:
+		return GRPCUnavailable
+	case fiber.StatusGatewayTimeout:
+		return GRPCDeadlineExceeded
+	}
+	if status >= 400 && status < 500 {
+		return GRPCFailedPrecondition
+	} else if status >= 500 {
+		return GRPCInternal
+	}
+	return GRPCUnknown
+}
+
+// GRPCCoder is implemented by errors carrying a gRPC code
+type GRPCCoder interface {
+	GRPCCode() GRPCCode
+}
+
+// StatusDetailer is implemented by errors carrying typed details, e.g. BadRequest, ErrorInfo or RetryInfo
+type StatusDetailer interface {
+	Details() []interface{}
+}
+
+// FieldViolation is an invalid field of a BadRequest
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// BadRequest is the google.rpc.BadRequest detail
+type BadRequest struct {
+	FieldViolations []FieldViolation
+}
+
+func (d BadRequest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type            string           `json:"@type"`
+		FieldViolations []FieldViolation `json:"fieldViolations"`
+	}{"type.googleapis.com/google.rpc.BadRequest", d.FieldViolations})
+}
+
+// ErrorInfo is the google.rpc.ErrorInfo detail
+type ErrorInfo struct {
+	Reason   string
+	Domain   string
+	Metadata map[string]string
+}
+
+func (d ErrorInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type     string            `json:"@type"`
+		Reason   string            `json:"reason"`
+		Domain   string            `json:"domain,omitempty"`
+		Metadata map[string]string `json:"metadata,omitempty"`
+	}{"type.googleapis.com/google.rpc.ErrorInfo", d.Reason, d.Domain, d.Metadata})
+}
+
+// RetryInfo is the google.rpc.RetryInfo detail
+type RetryInfo struct {
+	RetryDelay time.Duration
+}
+
+func (d RetryInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type       string `json:"@type"`
+		RetryDelay string `json:"retryDelay"`
+	}{"type.googleapis.com/google.rpc.RetryInfo", strconv.FormatFloat(d.RetryDelay.Seconds(), 'f', -1, 64) + "s"})
+}
+
+// StatusError is an HTTPError following the gRPC status model
+type StatusError struct {
+	*httpError
+	grpcCode GRPCCode
+	details  []interface{}
+}
+
+// NewStatusError returns an error with the HTTP status code mapped from the gRPC code
+func NewStatusError(code GRPCCode, message string, details ...interface{}) *StatusError {
+	return &StatusError{
+		httpError: NewHttpError(code.HTTPStatus(), message, nil),
+		grpcCode:  code,
+		details:   details,
+	}
+}
+
+// Copy of the error with another message
+func (se *StatusError) withMessage(message string) HTTPError {
+	cp := *se.httpError
+	cp.message = message
+	return &StatusError{httpError: &cp, grpcCode: se.grpcCode, details: se.details}
+}
+
+func (se *StatusError) GRPCCode() GRPCCode {
+	return se.grpcCode
+}
+
+func (se *StatusError) Details() []interface{} {
+	return se.details
+}
+
+// WithCode sets an application specific error code, rendered as the ErrorInfo reason
+func (se *StatusError) WithCode(code string) *StatusError {
+	se.httpError.WithCode(code)
+	return se
+}
+
+// WithRetry marks the error as retryable after the suggested delay, rendered as a RetryInfo detail
+func (se *StatusError) WithRetry(after time.Duration) *StatusError {
+	se.httpError.WithRetry(after)
+	return se
+}
+
+// WithMessageKey sets the key and arguments used to resolve a localised message from Config.Catalog
+func (se *StatusError) WithMessageKey(key string, args map[string]interface{}) *StatusError {
+	se.httpError.WithMessageKey(key, args)
+	return se
+}
+
+// Status is the google.rpc.Status of an error
+type Status struct {
+	Code    GRPCCode
+	Message string
+	Details []interface{}
+}
+
+// ToStatus converts an error to the gRPC status model.
+// The code is mapped from the HTTP status unless the error implements GRPCCoder,
+// invalid fields become a BadRequest detail, the error code an ErrorInfo detail and the retry delay a RetryInfo detail.
+func ToStatus(err error) Status {
+	httpErr := getHTTPError(err)
+	s := Status{
+		Code:    GRPCCodeFromStatus(httpErr.StatusCode()),
+		Message: httpErr.Message(),
+	}
+	var gc GRPCCoder
+	if errors.As(httpErr, &gc) {
+		s.Code = gc.GRPCCode()
+	}
+	var sd StatusDetailer
+	if errors.As(httpErr, &sd) {
+		s.Details = append(s.Details, sd.Details()...)
+	}
+
+	hasDetail := func(match func(interface{}) bool) bool {
+		for _, d := range s.Details {
+			if match(d) {
+				return true
+			}
+		}
+		return false
+	}
+	var fe FieldErrorer
+	if errors.As(httpErr, &fe) && len(fe.FieldErrors()) > 0 && !hasDetail(func(d interface{}) bool { _, ok := d.(BadRequest); return ok }) {
+		br := BadRequest{}
+		for _, f := range fe.FieldErrors() {
+			br.FieldViolations = append(br.FieldViolations, FieldViolation{Field: f.Field, Description: f.Message})
+		}
+		s.Details = append(s.Details, br)
+	}
+	if code := errorCode(httpErr); code != "" && !hasDetail(func(d interface{}) bool { _, ok := d.(ErrorInfo); return ok }) {
+		s.Details = append(s.Details, ErrorInfo{Reason: code})
+	}
+	if after := retryAfter(httpErr); after > 0 && !hasDetail(func(d interface{}) bool { _, ok := d.(RetryInfo); return ok }) {
+		s.Details = append(s.Details, RetryInfo{RetryDelay: after})
+	}
+	return s
+}
+
+// Send error in the Google API JSON error format `{"error":{"code","message","status","details":[...]}}`
+func handleGoogleAPI(c *fiber.Ctx, cfg Config, args ...interface{}) {
+	httpErr := getHTTPError(args...)
+	s := ToStatus(httpErr)
+	status := httpErr.StatusCode()
+
+	details := s.Details
+	if details == nil {
+		details = []interface{}{}
+	}
+	c.Status(status).JSON(fiber.Map{
+		"error": fiber.Map{
+			"code":    status,
+			"message": s.Message,
+			"status":  s.Code.String(),
+			"details": details,
+		},
+	})
+}
@@ -0,0 +1,82 @@
+package fiber_errhandler
+
+import (
+	"encoding/json"
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGRPCCode_mapping(t *testing.T) {
+	assert.Equal(t, fiber.StatusNotFound, GRPCNotFound.HTTPStatus())
+	assert.Equal(t, fiber.StatusForbidden, GRPCPermissionDenied.HTTPStatus())
+	assert.Equal(t, fiber.StatusServiceUnavailable, GRPCUnavailable.HTTPStatus())
+	assert.Equal(t, fiber.StatusInternalServerError, GRPCCode(99).HTTPStatus())
+	assert.Equal(t, "NOT_FOUND", GRPCNotFound.String())
+	assert.Equal(t, "CODE(99)", GRPCCode(99).String())
+
+	assert.Equal(t, GRPCNotFound, GRPCCodeFromStatus(fiber.StatusNotFound))
+	assert.Equal(t, GRPCInvalidArgument, GRPCCodeFromStatus(fiber.StatusUnprocessableEntity))
+	assert.Equal(t, GRPCResourceExhausted, GRPCCodeFromStatus(fiber.StatusTooManyRequests))
+	assert.Equal(t, GRPCFailedPrecondition, GRPCCodeFromStatus(fiber.StatusTeapot))
+	assert.Equal(t, GRPCInternal, GRPCCodeFromStatus(fiber.StatusBadGateway))
+}
+
+func TestErrHandler_googleapi(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(Config{
+		GoogleAPI: true,
+	}))
+	app.Get("/users/1", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusNotFound, "User not found", nil).WithCode("USER_NOT_FOUND"))
+	})
+	app.Post("/users", func(c *fiber.Ctx) {
+		c.Next(NewValidationError("Invalid user", FieldError{Field: "email", Message: "is required"}))
+	})
+	app.Get("/quota", func(c *fiber.Ctx) {
+		c.Next(NewStatusError(GRPCResourceExhausted, "Quota exceeded",
+			ErrorInfo{Reason: "RATE_LIMIT_EXCEEDED", Domain: "example.com", Metadata: map[string]string{"limit": "100"}},
+			RetryInfo{RetryDelay: 1500 * time.Millisecond}).WithCode("IGNORED"))
+	})
+
+	app.Get("/panic", func(c *fiber.Ctx) {
+		panic("boom")
+	})
+
+	for _, tc := range []struct {
+		method   string
+		target   string
+		status   int
+		expected string
+	}{
+		{"GET", "/users/1", fiber.StatusNotFound,
+			`{"error":{"code":404,"message":"User not found","status":"NOT_FOUND","details":[
+				{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"USER_NOT_FOUND"}]}}`},
+		{"POST", "/users", fiber.StatusUnprocessableEntity,
+			`{"error":{"code":422,"message":"Invalid user","status":"INVALID_ARGUMENT","details":[
+				{"@type":"type.googleapis.com/google.rpc.BadRequest","fieldViolations":[{"field":"email","description":"is required"}]}]}}`},
+		{"GET", "/quota", fiber.StatusTooManyRequests,
+			`{"error":{"code":429,"message":"Quota exceeded","status":"RESOURCE_EXHAUSTED","details":[
+				{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"RATE_LIMIT_EXCEEDED","domain":"example.com","metadata":{"limit":"100"}},
+				{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay":"1.5s"}]}}`},
+		{"GET", "/panic", fiber.StatusInternalServerError,
+			`{"error":{"code":500,"message":"boom","status":"INTERNAL","details":[]}}`},
+	} {
+		req := httptest.NewRequest(tc.method, tc.target, nil)
+		if resp, err := app.Test(req); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, tc.status, resp.StatusCode)
+			b := make(map[string]interface{})
+			expected := make(map[string]interface{})
+			assert.NoError(t, json.Unmarshal([]byte(tc.expected), &expected))
+			if err := json.NewDecoder(resp.Body).Decode(&b); err != nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, expected, b)
+			}
+		}
+	}
+}
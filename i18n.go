@@ -0,0 +1,391 @@
+package fiber_errhandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Localizable is implemented by errors whose message is resolved from a Catalog,
+// e.g. NewHttpError(...).WithMessageKey("user_not_found", map[string]interface{}{"ID": 42})
+type Localizable interface {
+	MessageKey() string
+	// Arguments of the message template, `Count` selects the plural form
+	MessageArgs() map[string]interface{}
+}
+
+// Plural forms of a message
+var pluralForms = map[string]bool{"zero": true, "one": true, "two": true, "few": true, "many": true, "other": true}
+
+// Catalog holds the localised messages of each locale.
+// Messages are `text/template` strings executed with the message arguments, e.g. `User {{.ID}} not found`.
+// A message may define plural forms (`zero`, `one`, `two`, `few`, `many`, `other`) selected by the `Count` argument.
+type Catalog struct {
+	fallback string
+
+	mu       sync.RWMutex
+	messages map[string]map[string]map[string]*template.Template
+	formats  map[string]Unmarshal
+}
+
+// Unmarshal decodes the messages of a catalog file, e.g. `yaml.Unmarshal` or `toml.Unmarshal`
+type Unmarshal func(data []byte, v interface{}) error
+
+// NewCatalog returns an empty catalog, messages missing in a locale are resolved from the fallback locale
+func NewCatalog(fallback string) *Catalog {
+	return &Catalog{
+		fallback: normalizeLocale(fallback),
+		messages: make(map[string]map[string]map[string]*template.Template),
+		formats:  map[string]Unmarshal{".json": json.Unmarshal},
+	}
+}
+
+// RegisterFormat adds a catalog file format by extension, e.g. `catalog.RegisterFormat(".yaml", yaml.Unmarshal)`.
+// JSON files are supported out of the box, YAML and TOML are registered by the i18n subpackage.
+func (c *Catalog) RegisterFormat(ext string, unmarshal Unmarshal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.formats[strings.ToLower(ext)] = unmarshal
+}
+
+// Add messages of a locale. Values are either a message, a map of plural forms,
+// or a map of nested messages whose keys are joined with a dot.
+func (c *Catalog) Add(locale string, messages map[string]interface{}) error {
+	parsed := make(map[string]map[string]*template.Template)
+	if err := parseMessages(parsed, "", messages); err != nil {
+		return fmt.Errorf("catalog %s: %w", locale, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	locale = normalizeLocale(locale)
+	if c.messages[locale] == nil {
+		c.messages[locale] = make(map[string]map[string]*template.Template)
+	}
+	for k, v := range parsed {
+		c.messages[locale][k] = v
+	}
+	return nil
+}
+
+func parseMessages(dst map[string]map[string]*template.Template, prefix string, messages map[string]interface{}) error {
+	for k, v := range messages {
+		key := prefix + k
+		switch v := v.(type) {
+		case string:
+			tmpl, err := template.New(key).Parse(v)
+			if err != nil {
+				return err
+			}
+			dst[key] = map[string]*template.Template{"other": tmpl}
+		case map[string]interface{}:
+			plural := len(v) > 0
+			for form := range v {
+				plural = plural && pluralForms[form]
+			}
+			if !plural {
+				if err := parseMessages(dst, key+".", v); err != nil {
+					return err
+				}
+				continue
+			}
+			forms := make(map[string]*template.Template)
+			for form, msg := range v {
+				s, ok := msg.(string)
+				if !ok {
+					return fmt.Errorf("message %s.%s is not a string", key, form)
+				}
+				tmpl, err := template.New(key + "." + form).Parse(s)
+				if err != nil {
+					return err
+				}
+				forms[form] = tmpl
+			}
+			dst[key] = forms
+		default:
+			return fmt.Errorf("message %s has unsupported type %T", key, v)
+		}
+	}
+	return nil
+}
+
+// LoadFile adds the messages of a file named after its locale, e.g. `fr.json`, see RegisterFormat
+func (c *Catalog) LoadFile(filename string) error {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return c.load(filepath.Base(filename), b)
+}
+
+// LoadFS adds the messages of all the files of a registered format in dir of fsys, e.g. an embed.FS
+func (c *Catalog) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || c.unmarshal(entry.Name()) == nil {
+			continue
+		}
+		b, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := c.load(entry.Name(), b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get the unmarshal func of a catalog file from its extension, nil if the format is not registered
+func (c *Catalog) unmarshal(name string) Unmarshal {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.formats[strings.ToLower(path.Ext(name))]
+}
+
+func (c *Catalog) load(name string, b []byte) error {
+	unmarshal := c.unmarshal(name)
+	if unmarshal == nil {
+		return fmt.Errorf("catalog %s: unsupported format", name)
+	}
+	messages := make(map[string]interface{})
+	if err := unmarshal(b, &messages); err != nil {
+		return fmt.Errorf("catalog %s: %w", name, err)
+	}
+	return c.Add(strings.TrimSuffix(name, path.Ext(name)), messages)
+}
+
+// Locales returns the locales having messages
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	locales := make([]string, 0, len(c.messages))
+	for l := range c.messages {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Match returns the supported locale closest to locale, e.g. `fr` for `fr-ca`, empty string if none
+func (c *Catalog) Match(locale string) string {
+	locale = normalizeLocale(locale)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, ok := c.messages[locale]; ok {
+		return locale
+	}
+	if i := strings.IndexByte(locale, '-'); i != -1 {
+		if _, ok := c.messages[locale[:i]]; ok {
+			return locale[:i]
+		}
+	}
+	return ""
+}
+
+// Localize resolves the message of key in locale, falling back to the fallback locale
+func (c *Catalog) Localize(locale string, key string, args map[string]interface{}) (string, bool) {
+	c.mu.RLock()
+	forms, ok := c.messages[normalizeLocale(locale)][key]
+	if !ok {
+		forms, ok = c.messages[c.fallback][key]
+		locale = c.fallback
+	}
+	c.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+
+	tmpl := forms["other"]
+	if count, ok := pluralCount(args); ok {
+		if f, ok := forms[pluralForm(locale, count)]; ok {
+			tmpl = f
+		}
+		if f, ok := forms["zero"]; ok && count == 0 {
+			tmpl = f
+		}
+	}
+	if tmpl == nil {
+		return "", false
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, args); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// Get the `Count` argument as integer
+func pluralCount(args map[string]interface{}) (int64, bool) {
+	switch n := args["Count"].(type) {
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint:
+		return int64(n), true
+	case float64:
+		return int64(n), true
+	case string:
+		i, err := strconv.ParseInt(n, 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// Select the plural form of n, covering the most common plural rules
+func pluralForm(locale string, n int64) string {
+	lang := locale
+	if i := strings.IndexByte(lang, '-'); i != -1 {
+		lang = lang[:i]
+	}
+	if n < 0 {
+		n = -n
+	}
+	switch lang {
+	case "ja", "zh", "ko", "vi", "th", "id", "ms":
+		return "other"
+	case "fr", "pt":
+		if n == 0 || n == 1 {
+			return "one"
+		}
+		return "other"
+	case "ru", "uk", "be", "sr", "hr", "bs":
+		if n%10 == 1 && n%100 != 11 {
+			return "one"
+		} else if n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14) {
+			return "few"
+		}
+		return "many"
+	case "pl":
+		if n == 1 {
+			return "one"
+		} else if n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14) {
+			return "few"
+		}
+		return "many"
+	case "cs", "sk":
+		if n == 1 {
+			return "one"
+		} else if n >= 2 && n <= 4 {
+			return "few"
+		}
+		return "other"
+	}
+	if n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// Parse `Accept-Language` header, return the languages by descending preference
+func parseAcceptLanguage(header string) []string {
+	type lang struct {
+		tag string
+		q   float64
+	}
+	var langs []lang
+	for _, val := range strings.Split(header, ",") {
+		q := 1.0
+		if i := strings.IndexByte(val, ';'); i != -1 {
+			if p := strings.TrimSpace(val[i+1:]); strings.HasPrefix(p, "q=") {
+				if f, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					q = f
+				}
+			}
+			val = val[:i]
+		}
+		if val = strings.TrimSpace(val); val != "" && val != "*" && q > 0 {
+			langs = append(langs, lang{val, q})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+	tags := make([]string, len(langs))
+	for i, l := range langs {
+		tags[i] = l.tag
+	}
+	return tags
+}
+
+// Resolve the locale of the request from the query, cookie or `Accept-Language` header.
+// Return the fallback locale if none is supported, empty string if there is no catalog.
+func getLocale(c *fiber.Ctx, cfg Config) string {
+	if cfg.Catalog == nil {
+		return ""
+	}
+	var candidates []string
+	if cfg.LocaleQuery != "" {
+		candidates = append(candidates, c.Query(cfg.LocaleQuery))
+	}
+	if cfg.LocaleCookie != "" {
+		candidates = append(candidates, c.Cookies(cfg.LocaleCookie))
+	}
+	candidates = append(candidates, parseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))...)
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if locale := cfg.Catalog.Match(candidate); locale != "" {
+			return locale
+		}
+	}
+	return cfg.Catalog.fallback
+}
+
+// Implemented by the errors of this package to copy themselves with a localised message,
+// so the error keeps its type and optional interfaces, e.g. Coder or FieldErrorer
+type messageCopier interface {
+	withMessage(message string) HTTPError
+}
+
+// HTTPError of another package with a localised message
+type localizedError struct {
+	HTTPError
+	message string
+}
+
+func (le *localizedError) Message() string {
+	return le.message
+}
+
+func (le *localizedError) Unwrap() error {
+	return le.HTTPError
+}
+
+// Resolve the message of a Localizable HTTPError in the request locale
+func localize(c *fiber.Ctx, cfg Config, err error) error {
+	var loc Localizable
+	var he HTTPError
+	if cfg.Catalog == nil || !errors.As(err, &loc) || !errors.As(err, &he) || loc.MessageKey() == "" {
+		return err
+	}
+	if msg, ok := cfg.Catalog.Localize(getLocale(c, cfg), loc.MessageKey(), loc.MessageArgs()); ok {
+		if mc, ok := he.(messageCopier); ok {
+			return mc.withMessage(msg)
+		}
+		return &localizedError{HTTPError: he, message: msg}
+	}
+	return err
+}
@@ -0,0 +1,22 @@
+module github.com/hendratommy/fiber-errhandler/i18n
+
+go 1.20
+
+require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/hendratommy/fiber-errhandler v0.0.0-20261018175843-cbcbb1f51161
+	github.com/stretchr/testify v1.8.4
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/andybalholm/brotli v1.0.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/gofiber/fiber v1.10.1 // indirect
+	github.com/gofiber/utils v0.0.3 // indirect
+	github.com/gorilla/schema v1.1.0 // indirect
+	github.com/klauspost/compress v1.10.6 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasthttp v1.13.1 // indirect
+)
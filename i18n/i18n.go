@@ -0,0 +1,29 @@
+// Package i18n loads fiber-errhandler catalogs from YAML and TOML files.
+//
+//	catalog := errhandler.NewCatalog("en")
+//	i18n.Register(catalog)
+//	err := catalog.LoadFS(locales, "locales")
+package i18n
+
+import (
+	"github.com/BurntSushi/toml"
+	errhandler "github.com/hendratommy/fiber-errhandler"
+	"gopkg.in/yaml.v3"
+)
+
+// YAML decodes the messages of a YAML catalog file
+func YAML(data []byte, v interface{}) error {
+	return yaml.Unmarshal(data, v)
+}
+
+// TOML decodes the messages of a TOML catalog file
+func TOML(data []byte, v interface{}) error {
+	return toml.Unmarshal(data, v)
+}
+
+// Register adds the `.yaml`, `.yml` and `.toml` formats to the catalog
+func Register(catalog *errhandler.Catalog) {
+	catalog.RegisterFormat(".yaml", YAML)
+	catalog.RegisterFormat(".yml", YAML)
+	catalog.RegisterFormat(".toml", TOML)
+}
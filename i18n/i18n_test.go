@@ -0,0 +1,33 @@
+package i18n
+
+import (
+	errhandler "github.com/hendratommy/fiber-errhandler"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	catalog := errhandler.NewCatalog("fr")
+	Register(catalog)
+	assert.NoError(t, catalog.LoadFS(os.DirFS("testdata"), "locales"))
+	assert.Equal(t, []string{"fr", "ru"}, catalog.Locales())
+
+	localize := func(locale string, key string, args map[string]interface{}) string {
+		msg, _ := catalog.Localize(locale, key, args)
+		return msg
+	}
+	assert.Equal(t, "Utilisateur 42 introuvable", localize("fr", "user_not_found", map[string]interface{}{"ID": 42}))
+	assert.Equal(t, "2 articles restants", localize("fr", "cart.items_left", map[string]interface{}{"Count": 2}))
+	assert.Equal(t, "Осталось 3 товара", localize("ru", "cart.items_left", map[string]interface{}{"Count": 3}))
+	// fallback locale
+	assert.Equal(t, "Utilisateur 42 introuvable", localize("ru", "user_not_found", map[string]interface{}{"ID": 42}))
+}
+
+func TestRegister_file(t *testing.T) {
+	catalog := errhandler.NewCatalog("en")
+	Register(catalog)
+	assert.NoError(t, catalog.LoadFile("testdata/locales/ru.toml"))
+	assert.Error(t, catalog.LoadFile("testdata/locales/missing.yml"))
+	assert.Equal(t, []string{"ru"}, catalog.Locales())
+}
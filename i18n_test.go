@@ -0,0 +1,174 @@
+package fiber_errhandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Decode `a.b = message` lines into nested messages
+func unmarshalProperties(data []byte, v interface{}) error {
+	messages := *v.(*map[string]interface{})
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid line %q", line)
+		}
+		keys := strings.Split(strings.TrimSpace(kv[0]), ".")
+		m := messages
+		for _, k := range keys[:len(keys)-1] {
+			if _, ok := m[k]; !ok {
+				m[k] = make(map[string]interface{})
+			}
+			m = m[k].(map[string]interface{})
+		}
+		m[keys[len(keys)-1]] = strings.TrimSpace(kv[1])
+	}
+	return nil
+}
+
+func newCatalog(t *testing.T) *Catalog {
+	catalog := NewCatalog("en")
+	catalog.RegisterFormat(".properties", unmarshalProperties)
+	assert.NoError(t, catalog.LoadFS(os.DirFS("testdata"), "locales"))
+	return catalog
+}
+
+func TestCatalog(t *testing.T) {
+	catalog := newCatalog(t)
+	assert.Equal(t, []string{"en", "fr", "ru"}, catalog.Locales())
+
+	localize := func(locale string, key string, args map[string]interface{}) string {
+		msg, _ := catalog.Localize(locale, key, args)
+		return msg
+	}
+	assert.Equal(t, "User 42 not found", localize("en", "user_not_found", map[string]interface{}{"ID": 42}))
+	assert.Equal(t, "Utilisateur 42 introuvable", localize("fr", "user_not_found", map[string]interface{}{"ID": 42}))
+	// fallback locale
+	assert.Equal(t, "User 42 not found", localize("ru", "user_not_found", map[string]interface{}{"ID": 42}))
+
+	assert.Equal(t, "No item left", localize("en", "cart.items_left", map[string]interface{}{"Count": 0}))
+	assert.Equal(t, "1 item left", localize("en", "cart.items_left", map[string]interface{}{"Count": 1}))
+	assert.Equal(t, "5 items left", localize("en", "cart.items_left", map[string]interface{}{"Count": 5}))
+	assert.Equal(t, "0 article restant", localize("fr", "cart.items_left", map[string]interface{}{"Count": 0}))
+	assert.Equal(t, "2 articles restants", localize("fr", "cart.items_left", map[string]interface{}{"Count": 2}))
+	assert.Equal(t, "Остался 21 товар", localize("ru", "cart.items_left", map[string]interface{}{"Count": 21}))
+	assert.Equal(t, "Осталось 3 товара", localize("ru", "cart.items_left", map[string]interface{}{"Count": 3}))
+	assert.Equal(t, "Осталось 11 товаров", localize("ru", "cart.items_left", map[string]interface{}{"Count": 11}))
+
+	_, ok := catalog.Localize("en", "missing", nil)
+	assert.False(t, ok)
+}
+
+func TestCatalog_unsupported_format(t *testing.T) {
+	catalog := NewCatalog("en")
+	assert.EqualError(t, catalog.LoadFile("testdata/locales/ru.properties"), "catalog ru.properties: unsupported format")
+	// files of unregistered formats are skipped
+	assert.NoError(t, catalog.LoadFS(os.DirFS("testdata"), "locales"))
+	assert.Equal(t, []string{"en", "fr"}, catalog.Locales())
+}
+
+func TestErrHandler_localized_keeps_type(t *testing.T) {
+	var coder, fieldErrorer, retryable bool
+	app := fiber.New()
+	app.Use(New(Config{
+		Catalog: newCatalog(t),
+		Handler: func(c *fiber.Ctx, err error, next func(...interface{})) {
+			_, coder = err.(Coder)
+			_, fieldErrorer = err.(FieldErrorer)
+			if r, ok := err.(Retryable); ok {
+				retryable = r.Retryable()
+			}
+			next(err)
+		},
+	}))
+	app.Get("/users/:id", func(c *fiber.Ctx) {
+		c.Next(NewValidationError("User not found", FieldError{Field: "id", Message: "unknown"}).
+			WithCode("USER_NOT_FOUND").
+			WithMessageKey("user_not_found", map[string]interface{}{"ID": c.Params("id")}))
+	})
+	app.Get("/retry", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusServiceUnavailable, "Busy", nil).
+			WithRetry(time.Second).
+			WithMessageKey("user_not_found", map[string]interface{}{"ID": 1}))
+	})
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept-Language", "fr")
+	if resp, err := app.Test(req); assert.NoError(t, err) {
+		b := make(map[string]interface{})
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&b))
+		assert.Equal(t, "Utilisateur 42 introuvable", b["message"])
+		assert.True(t, coder)
+		assert.True(t, fieldErrorer)
+	}
+
+	req = httptest.NewRequest("GET", "/retry", nil)
+	req.Header.Set("Accept-Language", "fr")
+	if resp, err := app.Test(req); assert.NoError(t, err) {
+		assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+		assert.True(t, retryable)
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{"fr-CH", "fr", "en", "de"}, parseAcceptLanguage("fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5"))
+	assert.Equal(t, []string{"en", "fr"}, parseAcceptLanguage("fr;q=0.5, en, ru;q=0"))
+}
+
+func TestErrHandler_localized(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(Config{
+		Catalog:      newCatalog(t),
+		LocaleQuery:  "lang",
+		LocaleCookie: "lang",
+	}))
+	app.Get("/users/:id", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusNotFound, "User not found", nil).
+			WithCode("USER_NOT_FOUND").
+			WithMessageKey("user_not_found", map[string]interface{}{"ID": c.Params("id")}))
+	})
+
+	for _, tc := range []struct {
+		url      string
+		lang     string
+		cookie   string
+		expected string
+	}{
+		{"/users/42", "fr-CA,fr;q=0.9,en;q=0.8", "", "Utilisateur 42 introuvable"},
+		{"/users/42", "de", "", "User 42 not found"},
+		{"/users/42?lang=fr", "en", "", "Utilisateur 42 introuvable"},
+		{"/users/42", "en", "fr", "Utilisateur 42 introuvable"},
+	} {
+		req := httptest.NewRequest("GET", tc.url, nil)
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Accept-Language", tc.lang)
+		if tc.cookie != "" {
+			req.Header.Set("Cookie", "lang="+tc.cookie)
+		}
+		if resp, err := app.Test(req); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+			b := make(map[string]interface{})
+			if err := json.NewDecoder(resp.Body).Decode(&b); err != nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, map[string]interface{}{
+					"message": tc.expected,
+					"code":    "USER_NOT_FOUND",
+				}, b)
+			}
+		}
+	}
+}
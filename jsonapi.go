@@ -0,0 +1,84 @@
+package fiber_errhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gofiber/fiber"
+	"net/http"
+	"strconv"
+)
+
+// MIMEApplicationJSONAPI is the media type of JSON:API documents
+const MIMEApplicationJSONAPI = "application/vnd.api+json"
+
+type jsonAPISource struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+}
+
+type jsonAPIError struct {
+	ID     string                 `json:"id,omitempty"`
+	Status string                 `json:"status"`
+	Code   string                 `json:"code,omitempty"`
+	Title  string                 `json:"title"`
+	Detail string                 `json:"detail,omitempty"`
+	Source *jsonAPISource         `json:"source,omitempty"`
+	Meta   map[string]interface{} `json:"meta,omitempty"`
+}
+
+// Send error as JSON:API `errors` array, one error object per invalid field of a FieldErrorer.
+// The error id is the request ID, suffixed with the index of the field when there are several error objects.
+func handleJSONAPI(c *fiber.Ctx, cfg Config, args ...interface{}) {
+	httpErr := getHTTPError(args...)
+	status := httpErr.StatusCode()
+
+	base := jsonAPIError{
+		ID:     requestID(c),
+		Status: strconv.Itoa(status),
+		Code:   errorCode(httpErr),
+		Title:  http.StatusText(status),
+		Detail: httpErr.Message(),
+	}
+	if traceID := getTraceID(c, cfg); traceID != "" {
+		base.Meta = map[string]interface{}{"traceId": traceID}
+	}
+
+	var errs []jsonAPIError
+	var fe FieldErrorer
+	if errors.As(httpErr, &fe) && len(fe.FieldErrors()) > 0 {
+		for i, f := range fe.FieldErrors() {
+			e := base
+			if e.ID != "" {
+				e.ID += "-" + strconv.Itoa(i)
+			}
+			e.Detail = f.Message
+			if f.Code != "" {
+				e.Code = f.Code
+			}
+			if f.Query {
+				e.Source = &jsonAPISource{Parameter: f.Field}
+			} else {
+				e.Source = &jsonAPISource{Pointer: f.pointer()}
+			}
+			errs = append(errs, e)
+		}
+	} else {
+		if httpErr.Data() != nil {
+			meta := map[string]interface{}{"error": httpErr.Data()}
+			for k, v := range base.Meta {
+				meta[k] = v
+			}
+			base.Meta = meta
+		}
+		errs = append(errs, base)
+	}
+
+	body, err := json.Marshal(fiber.Map{"errors": errs})
+	if err != nil {
+		c.SendStatus(fiber.StatusInternalServerError)
+		return
+	}
+	c.Status(status)
+	c.Set(fiber.HeaderContentType, MIMEApplicationJSONAPI)
+	c.SendBytes(body)
+}
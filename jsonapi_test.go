@@ -0,0 +1,91 @@
+package fiber_errhandler
+
+import (
+	"encoding/json"
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrHandler_jsonapi(t *testing.T) {
+	app := fiber.New()
+	app.Use(New())
+	app.Post("/users", func(c *fiber.Ctx) {
+		c.Next(NewValidationError("Invalid user",
+			FieldError{Field: "email", Message: "must be a valid email", Code: "INVALID_EMAIL"},
+			FieldError{Field: "address.city", Message: "is required"},
+			FieldError{Field: "include", Message: "unknown relationship", Query: true},
+		).WithCode("VALIDATION_FAILED"))
+	})
+	app.Get("/users/:id", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusNotFound, "User not found", fiber.Map{"id": c.Params("id")}).WithCode("USER_NOT_FOUND"))
+	})
+
+	req := httptest.NewRequest("POST", "/users", nil)
+	req.Header.Set("Content-Type", MIMEApplicationJSONAPI)
+	req.Header.Set("X-Request-ID", "abc123")
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+		assert.Equal(t, MIMEApplicationJSONAPI, resp.Header.Get(fiber.HeaderContentType))
+		b := make(map[string]interface{})
+		if err := json.NewDecoder(resp.Body).Decode(&b); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, map[string]interface{}{
+				"errors": []interface{}{
+					map[string]interface{}{
+						"id":     "abc123-0",
+						"status": "422",
+						"code":   "INVALID_EMAIL",
+						"title":  "Unprocessable Entity",
+						"detail": "must be a valid email",
+						"source": map[string]interface{}{"pointer": "/data/attributes/email"},
+					},
+					map[string]interface{}{
+						"id":     "abc123-1",
+						"status": "422",
+						"code":   "VALIDATION_FAILED",
+						"title":  "Unprocessable Entity",
+						"detail": "is required",
+						"source": map[string]interface{}{"pointer": "/data/attributes/address/city"},
+					},
+					map[string]interface{}{
+						"id":     "abc123-2",
+						"status": "422",
+						"code":   "VALIDATION_FAILED",
+						"title":  "Unprocessable Entity",
+						"detail": "unknown relationship",
+						"source": map[string]interface{}{"parameter": "include"},
+					},
+				},
+			}, b)
+		}
+	}
+
+	req = httptest.NewRequest("GET", "/users/42", nil)
+	req.Header.Set("Accept", MIMEApplicationJSONAPI)
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		b := make(map[string]interface{})
+		if err := json.NewDecoder(resp.Body).Decode(&b); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, map[string]interface{}{
+				"errors": []interface{}{
+					map[string]interface{}{
+						"status": "404",
+						"code":   "USER_NOT_FOUND",
+						"title":  "Not Found",
+						"detail": "User not found",
+						"meta":   map[string]interface{}{"error": map[string]interface{}{"id": "42"}},
+					},
+				},
+			}, b)
+		}
+	}
+}
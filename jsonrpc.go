@@ -0,0 +1,84 @@
+package fiber_errhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gofiber/fiber"
+)
+
+// JSON-RPC 2.0 reserved error codes
+const (
+	RPCParseError     = -32700
+	RPCInvalidRequest = -32600
+	RPCMethodNotFound = -32601
+	RPCInvalidParams  = -32602
+	RPCInternalError  = -32603
+)
+
+type jsonRPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type jsonRPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Error   jsonRPCError    `json:"error"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// Get the id of the JSON-RPC request, `null` if the body is not a single request.
+// ok is false if the body is not valid JSON.
+func jsonRPCRequestID(c *fiber.Ctx) (id json.RawMessage, ok bool) {
+	id = json.RawMessage("null")
+	var req struct {
+		ID json.RawMessage `json:"id"`
+	}
+	body := c.Fasthttp.Request.Body()
+	if !json.Valid(body) {
+		return id, false
+	}
+	if err := json.Unmarshal(body, &req); err == nil && len(req.ID) > 0 {
+		id = req.ID
+	}
+	return id, true
+}
+
+// Map the status code to a JSON-RPC reserved error code
+func jsonRPCCode(status int) int {
+	switch status {
+	case fiber.StatusBadRequest:
+		return RPCInvalidRequest
+	case fiber.StatusNotFound, fiber.StatusMethodNotAllowed:
+		return RPCMethodNotFound
+	case fiber.StatusUnprocessableEntity:
+		return RPCInvalidParams
+	}
+	return RPCInternalError
+}
+
+// Send error as JSON-RPC 2.0 error response, echoing the request id.
+// The status code is always 200, the failure being described by the error code.
+func handleJSONRPC(c *fiber.Ctx, cfg Config, args ...interface{}) {
+	httpErr := getHTTPError(args...)
+
+	id, valid := jsonRPCRequestID(c)
+	code := jsonRPCCode(httpErr.StatusCode())
+	if !valid && httpErr.StatusCode() == fiber.StatusBadRequest {
+		code = RPCParseError
+	}
+	var rc RPCCoder
+	if errors.As(httpErr, &rc) && rc.RPCCode() != 0 {
+		code = rc.RPCCode()
+	}
+
+	c.Status(fiber.StatusOK).JSON(jsonRPCResponse{
+		JSONRPC: "2.0",
+		Error: jsonRPCError{
+			Code:    code,
+			Message: httpErr.Message(),
+			Data:    httpErr.Data(),
+		},
+		ID: id,
+	})
+}
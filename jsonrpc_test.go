@@ -0,0 +1,64 @@
+package fiber_errhandler
+
+import (
+	"encoding/json"
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestErrHandler_jsonrpc(t *testing.T) {
+	app := fiber.New()
+	app.Use(New())
+	app.Post("/rpc", Override(func(cfg *Config) {
+		cfg.JSONRPC = true
+	}), func(c *fiber.Ctx) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		if err := json.Unmarshal([]byte(c.Body()), &req); err != nil {
+			c.Next(NewHttpError(fiber.StatusBadRequest, "Parse error", nil))
+			return
+		}
+		switch req.Method {
+		case "user.get":
+			c.Next(NewHttpError(fiber.StatusNotFound, "User not found", nil).WithRPCCode(-32004))
+		case "user.create":
+			c.Next(NewValidationError("Invalid params", FieldError{Field: "email", Message: "is required"}))
+		default:
+			c.Next(NewHttpError(fiber.StatusNotFound, "Method not found", nil))
+		}
+	})
+
+	for _, tc := range []struct {
+		body     string
+		expected string
+	}{
+		{`{"jsonrpc":"2.0","method":"user.get","id":1}`,
+			`{"jsonrpc":"2.0","error":{"code":-32004,"message":"User not found"},"id":1}`},
+		{`{"jsonrpc":"2.0","method":"user.create","id":"abc"}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params","data":[{"field":"email","message":"is required"}]},"id":"abc"}`},
+		{`{"jsonrpc":"2.0","method":"user.delete","id":2}`,
+			`{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":2}`},
+		{`{"jsonrpc":"2.0","method"`,
+			`{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"},"id":null}`},
+	} {
+		req := httptest.NewRequest("POST", "/rpc", strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		if resp, err := app.Test(req); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			b := make(map[string]interface{})
+			expected := make(map[string]interface{})
+			assert.NoError(t, json.Unmarshal([]byte(tc.expected), &expected))
+			if err := json.NewDecoder(resp.Body).Decode(&b); err != nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, expected, b)
+			}
+		}
+	}
+}
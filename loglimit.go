@@ -0,0 +1,180 @@
+package fiber_errhandler
+
+import (
+	"errors"
+	"github.com/gofiber/fiber"
+	"io"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LogLimiterConfig ...
+type LogLimiterConfig struct {
+	// Rate is the number of log lines per second allowed for each fingerprint
+	// Optional. Default: 1
+	Rate float64
+	// Burst is the number of log lines allowed at once for each fingerprint
+	// Optional. Default: 5
+	Burst int
+	// Sample4xx is the probability a 4xx error is logged, sampled out errors are counted as suppressed
+	// Optional. Default: 1
+	Sample4xx float64
+}
+
+type logBucket struct {
+	tokens     float64
+	last       time.Time
+	suppressed int
+	// flushes the summary once the bucket is refilled
+	flush *time.Timer
+}
+
+// LogLimiter rate-limits `Config.Log` with a token bucket per fingerprint.
+// Suppressed errors are summarised in a "suppressed N similar errors" line, written before the next logged error
+// of the fingerprint or once its bucket is refilled, whichever comes first.
+type LogLimiter struct {
+	cfg LogLimiterConfig
+
+	mu      sync.Mutex
+	buckets map[string]*logBucket
+}
+
+// Number of buckets above which idle buckets are removed
+const pruneBuckets = 1024
+
+// NewLogLimiter ...
+func NewLogLimiter(config ...LogLimiterConfig) *LogLimiter {
+	var cfg LogLimiterConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Rate <= 0 {
+		cfg.Rate = 1
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = 5
+	}
+	if cfg.Sample4xx <= 0 || cfg.Sample4xx > 1 {
+		cfg.Sample4xx = 1
+	}
+	return &LogLimiter{
+		cfg:     cfg,
+		buckets: make(map[string]*logBucket),
+	}
+}
+
+// Decide whether the event is logged, return the number of similar errors suppressed since the last logged one.
+// Summaries pending when a bucket is refilled or pruned are written to out.
+func (l *LogLimiter) allow(e *Event, status int, out io.Writer) (bool, int) {
+	sampled := status < 400 || status >= 500 || l.cfg.Sample4xx >= 1 || rand.Float64() < l.cfg.Sample4xx
+
+	l.mu.Lock()
+	var pruned map[string]int
+	defer func() {
+		l.mu.Unlock()
+		if out != nil {
+			for fp, n := range pruned {
+				writeSummary(out, fp, n)
+			}
+		}
+	}()
+
+	now := e.Time
+	b, ok := l.buckets[e.Fingerprint]
+	if !ok {
+		if len(l.buckets) >= pruneBuckets {
+			pruned = l.prune(now)
+		}
+		b = &logBucket{tokens: float64(l.cfg.Burst), last: now}
+		l.buckets[e.Fingerprint] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.cfg.Rate
+	if b.tokens > float64(l.cfg.Burst) {
+		b.tokens = float64(l.cfg.Burst)
+	}
+	b.last = now
+
+	if !sampled || b.tokens < 1 {
+		b.suppressed++
+		if b.flush == nil && out != nil {
+			refill := 1 / l.cfg.Rate
+			if b.tokens < 1 {
+				refill = (1 - b.tokens) / l.cfg.Rate
+			}
+			fp := e.Fingerprint
+			b.flush = time.AfterFunc(time.Duration(refill*float64(time.Second)), func() {
+				l.flush(fp, b, out)
+			})
+		}
+		return false, 0
+	}
+	b.tokens--
+	if b.flush != nil {
+		b.flush.Stop()
+		b.flush = nil
+	}
+	suppressed := b.suppressed
+	b.suppressed = 0
+	return true, suppressed
+}
+
+// Write the pending summary of the bucket once it is refilled
+func (l *LogLimiter) flush(fp string, b *logBucket, out io.Writer) {
+	l.mu.Lock()
+	suppressed := b.suppressed
+	b.suppressed = 0
+	b.flush = nil
+	l.mu.Unlock()
+	if suppressed > 0 {
+		writeSummary(out, fp, suppressed)
+	}
+}
+
+// Remove the buckets which are full, return the pending summaries of the removed buckets
+func (l *LogLimiter) prune(now time.Time) map[string]int {
+	pruned := make(map[string]int)
+	for fp, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.cfg.Rate >= float64(l.cfg.Burst) {
+			if b.flush != nil {
+				b.flush.Stop()
+				b.flush = nil
+			}
+			if b.suppressed > 0 {
+				pruned[fp] = b.suppressed
+				b.suppressed = 0
+			}
+			delete(l.buckets, fp)
+		}
+	}
+	return pruned
+}
+
+// Get the status code the error will be rendered with by default
+func errorStatus(err error) int {
+	var he HTTPError
+	if errors.As(err, &he) {
+		return he.StatusCode()
+	}
+	return fiber.StatusInternalServerError
+}
+
+// Write the log line of the event to `cfg.Output`, applying `cfg.LogLimiter`
+func writeLog(cfg Config, e *Event, line string) {
+	if cfg.LogLimiter != nil {
+		ok, suppressed := cfg.LogLimiter.allow(e, errorStatus(e.Err), cfg.Output)
+		if !ok {
+			return
+		}
+		if suppressed > 0 {
+			writeSummary(cfg.Output, e.Fingerprint, suppressed)
+		}
+	}
+	cfg.Output.Write([]byte(line))
+}
+
+// Write the summary of the errors suppressed by LogLimiter
+func writeSummary(out io.Writer, fingerprint string, suppressed int) {
+	out.Write([]byte("suppressed " + strconv.Itoa(suppressed) + " similar errors (fingerprint: " + fingerprint + ")\n"))
+}
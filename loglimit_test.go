@@ -0,0 +1,188 @@
+package fiber_errhandler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Buffer safe for summaries written by the LogLimiter timers
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLogLimiter(t *testing.T) {
+	out := &bytes.Buffer{}
+	app := fiber.New()
+	app.Use(New(Config{
+		Log:    true,
+		Output: out,
+		LogLimiter: NewLogLimiter(LogLimiterConfig{
+			Rate:  0.001,
+			Burst: 2,
+		}),
+	}))
+	app.Get("/err", func(c *fiber.Ctx) {
+		c.Next(errors.New("bad thing happens"))
+	})
+
+	for i := 0; i < 5; i++ {
+		if _, err := app.Test(httptest.NewRequest("GET", "/err", nil)); err != nil {
+			assert.NoError(t, err)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.True(t, strings.HasPrefix(lines[0], "bad thing happens (fingerprint: "))
+	}
+}
+
+func TestLogLimiter_suppressed_summary(t *testing.T) {
+	limiter := NewLogLimiter(LogLimiterConfig{
+		Rate:  1,
+		Burst: 1,
+	})
+	now := time.Now()
+	e := &Event{Time: now, Fingerprint: "fp"}
+
+	ok, suppressed := limiter.allow(e, fiber.StatusInternalServerError, nil)
+	assert.True(t, ok)
+	assert.Equal(t, 0, suppressed)
+	for i := 0; i < 3; i++ {
+		ok, _ = limiter.allow(e, fiber.StatusInternalServerError, nil)
+		assert.False(t, ok)
+	}
+
+	e.Time = now.Add(time.Second)
+	ok, suppressed = limiter.allow(e, fiber.StatusInternalServerError, nil)
+	assert.True(t, ok)
+	assert.Equal(t, 3, suppressed)
+}
+
+func TestLogLimiter_sample_4xx(t *testing.T) {
+	limiter := NewLogLimiter(LogLimiterConfig{
+		Rate:      1000,
+		Burst:     1000,
+		Sample4xx: 0.000001,
+	})
+	e := &Event{Time: time.Now(), Fingerprint: "fp"}
+
+	logged := 0
+	for i := 0; i < 100; i++ {
+		if ok, _ := limiter.allow(e, fiber.StatusBadRequest, nil); ok {
+			logged++
+		}
+	}
+	assert.Less(t, logged, 5)
+
+	ok, suppressed := limiter.allow(e, fiber.StatusInternalServerError, nil)
+	assert.True(t, ok)
+	assert.Equal(t, 100-logged, suppressed)
+}
+
+func TestLogLimiter_response_started(t *testing.T) {
+	out := &lockedBuffer{}
+	app := fiber.New()
+	app.Use(New(Config{
+		Log:    true,
+		Output: out,
+		LogLimiter: NewLogLimiter(LogLimiterConfig{
+			Rate:  10,
+			Burst: 1,
+		}),
+	}))
+	app.Get("/partial", func(c *fiber.Ctx) {
+		c.SendString("partial body")
+		c.Next(errors.New("bad thing happens"))
+	})
+	request := func() {
+		if _, err := app.Test(httptest.NewRequest("GET", "/partial", nil)); err != nil {
+			assert.NoError(t, err)
+		}
+	}
+
+	for i := 0; i < 4; i++ {
+		request()
+	}
+	// wait for the bucket to refill, the next logged error is preceded by the summary
+	time.Sleep(150 * time.Millisecond)
+	request()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.True(t, strings.HasPrefix(lines[0], "bad thing happens (response already started, fingerprint: "))
+		assert.True(t, strings.HasPrefix(lines[1], "suppressed 3 similar errors (fingerprint: "))
+		assert.True(t, strings.HasPrefix(lines[2], "bad thing happens (response already started, fingerprint: "))
+	}
+}
+
+func TestLogLimiter_flush_on_refill(t *testing.T) {
+	out := &lockedBuffer{}
+	app := fiber.New()
+	app.Use(New(Config{
+		Log:    true,
+		Output: out,
+		LogLimiter: NewLogLimiter(LogLimiterConfig{
+			Rate:  10,
+			Burst: 1,
+		}),
+	}))
+	app.Get("/err", func(c *fiber.Ctx) {
+		c.Next(errors.New("bad thing happens"))
+	})
+
+	for i := 0; i < 4; i++ {
+		if _, err := app.Test(httptest.NewRequest("GET", "/err", nil)); err != nil {
+			assert.NoError(t, err)
+		}
+	}
+	// the burst is over, the summary is written once the bucket is refilled
+	time.Sleep(150 * time.Millisecond)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.True(t, strings.HasPrefix(lines[0], "bad thing happens (fingerprint: "))
+		assert.True(t, strings.HasPrefix(lines[1], "suppressed 3 similar errors (fingerprint: "))
+	}
+}
+
+func TestLogLimiter_prune(t *testing.T) {
+	out := &lockedBuffer{}
+	limiter := NewLogLimiter(LogLimiterConfig{
+		Rate:  1,
+		Burst: 1,
+	})
+	now := time.Now()
+	for i := 0; i < pruneBuckets; i++ {
+		e := &Event{Time: now, Fingerprint: fmt.Sprint(i)}
+		limiter.allow(e, fiber.StatusInternalServerError, out)
+		limiter.allow(e, fiber.StatusInternalServerError, out)
+	}
+	assert.Empty(t, out.String())
+
+	// stale buckets are removed once their summary is written
+	limiter.allow(&Event{Time: now.Add(time.Minute), Fingerprint: "new"}, fiber.StatusInternalServerError, out)
+	assert.Len(t, limiter.buckets, 1)
+	assert.Len(t, strings.Split(strings.TrimSpace(out.String()), "\n"), pruneBuckets)
+	assert.Contains(t, out.String(), "suppressed 1 similar errors (fingerprint: 0)\n")
+}
@@ -0,0 +1,184 @@
+package fiber_errhandler
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// MaintenanceConfig ...
+type MaintenanceConfig struct {
+	// Filter defines a function to bypass maintenance mode.
+	// Optional. Default: nil
+	Filter func(*fiber.Ctx) bool
+	// AllowPaths are the paths served during maintenance, a trailing `*` matches any suffix, e.g. `/admin/*`
+	// Optional. Default: nil
+	AllowPaths []string
+	// AllowIPs are the client IPs or CIDR ranges served during maintenance, NewMaintenance fails on invalid entries
+	// Optional. Default: nil
+	AllowIPs []string
+	// File enables maintenance mode while it exists
+	// Optional. Default: ""
+	File string
+	// FileCheckInterval is how long the existence of File is cached
+	// Optional. Default: 1 second
+	FileCheckInterval time.Duration
+	// Env enables maintenance mode while the environment variable is true, e.g. `MAINTENANCE=1`
+	// Optional. Default: ""
+	Env string
+	// RetryAfter is sent as `Retry-After` with the 503 response
+	// Optional. Default: 1 minute
+	RetryAfter time.Duration
+	// Message of the 503 error
+	// Optional. Default: "Service Unavailable"
+	Message string
+}
+
+// Maintenance short-circuits requests with a 503 error while enabled, see `Config.Maintenance`.
+// It is enabled by Enable, by `File` existing or by `Env` being true.
+// Enable and Env are checked on every request, the existence of File is cached for `FileCheckInterval`.
+type Maintenance struct {
+	cfg     MaintenanceConfig
+	enabled int32
+	ips     []*net.IPNet
+
+	// cached existence of cfg.File, with the time it was checked in unix nanoseconds
+	fileExists  int32
+	fileChecked int64
+}
+
+// NewMaintenance ...
+func NewMaintenance(config ...MaintenanceConfig) (*Maintenance, error) {
+	var cfg MaintenanceConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.RetryAfter <= 0 {
+		cfg.RetryAfter = time.Minute
+	}
+	if cfg.Message == "" {
+		cfg.Message = http.StatusText(fiber.StatusServiceUnavailable)
+	}
+	if cfg.FileCheckInterval <= 0 {
+		cfg.FileCheckInterval = time.Second
+	}
+
+	m := &Maintenance{cfg: cfg}
+	for _, ip := range cfg.AllowIPs {
+		cidr := ip
+		if !strings.Contains(cidr, "/") {
+			if strings.Contains(cidr, ":") {
+				cidr += "/128"
+			} else {
+				cidr += "/32"
+			}
+		}
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed IP %q: %w", ip, err)
+		}
+		m.ips = append(m.ips, n)
+	}
+	return m, nil
+}
+
+// Enable turns maintenance mode on
+func (m *Maintenance) Enable() {
+	atomic.StoreInt32(&m.enabled, 1)
+}
+
+// Disable turns maintenance mode off, it stays on while `File` exists or `Env` is true
+func (m *Maintenance) Disable() {
+	atomic.StoreInt32(&m.enabled, 0)
+}
+
+// Enabled reports whether maintenance mode is on
+func (m *Maintenance) Enabled() bool {
+	if atomic.LoadInt32(&m.enabled) == 1 {
+		return true
+	}
+	if m.cfg.Env != "" {
+		if on, _ := strconv.ParseBool(os.Getenv(m.cfg.Env)); on {
+			return true
+		}
+	}
+	return m.cfg.File != "" && m.fileFound()
+}
+
+// Report whether cfg.File exists, checking the file system at most once per FileCheckInterval
+func (m *Maintenance) fileFound() bool {
+	now := time.Now().UnixNano()
+	checked := atomic.LoadInt64(&m.fileChecked)
+	if now-checked < int64(m.cfg.FileCheckInterval) {
+		return atomic.LoadInt32(&m.fileExists) == 1
+	}
+	// a single request refreshes the cache, concurrent ones use the previous result
+	if !atomic.CompareAndSwapInt64(&m.fileChecked, checked, now) {
+		return atomic.LoadInt32(&m.fileExists) == 1
+	}
+	exists := int32(0)
+	if _, err := os.Stat(m.cfg.File); err == nil {
+		exists = 1
+	}
+	atomic.StoreInt32(&m.fileExists, exists)
+	return exists == 1
+}
+
+// Report whether the request bypasses maintenance mode
+func (m *Maintenance) allowed(c *fiber.Ctx) bool {
+	if m.cfg.Filter != nil && m.cfg.Filter(c) {
+		return true
+	}
+	path := c.Path()
+	for _, p := range m.cfg.AllowPaths {
+		if p == path || (strings.HasSuffix(p, "*") && strings.HasPrefix(path, p[:len(p)-1])) {
+			return true
+		}
+	}
+	if len(m.ips) > 0 {
+		if ip := net.ParseIP(c.IP()); ip != nil {
+			for _, n := range m.ips {
+				if n.Contains(ip) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Report whether the request must be short-circuited
+func (m *Maintenance) active(c *fiber.Ctx) bool {
+	return m.Enabled() && !m.allowed(c)
+}
+
+// Error sent while maintenance mode is on
+func (m *Maintenance) err() HTTPError {
+	return NewHttpError(fiber.StatusServiceUnavailable, m.cfg.Message, nil).
+		WithCode("MAINTENANCE").
+		WithRetry(m.cfg.RetryAfter)
+}
+
+// Handler returns a handler to toggle maintenance mode:
+// `POST` and `PUT` enable it, `DELETE` disables it and any method responds with the current state.
+// The handler does no authentication: mount it behind an auth middleware, on a path listed in `AllowPaths`
+// so it is still served to disable maintenance mode.
+func (m *Maintenance) Handler() func(*fiber.Ctx) {
+	return func(c *fiber.Ctx) {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut:
+			m.Enable()
+		case fiber.MethodDelete:
+			m.Disable()
+		}
+		c.JSON(fiber.Map{
+			"enabled": m.Enabled(),
+		})
+	}
+}
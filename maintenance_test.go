@@ -0,0 +1,139 @@
+package fiber_errhandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestErrHandler_maintenance(t *testing.T) {
+	m, err := NewMaintenance(MaintenanceConfig{
+		AllowPaths: []string{"/health", "/admin/*"},
+		RetryAfter: 2 * time.Minute,
+	})
+	assert.NoError(t, err)
+	out := &bytes.Buffer{}
+	observed := 0
+	app := fiber.New()
+	app.Use(New(Config{
+		Maintenance: m,
+		ContentType: fiber.MIMEApplicationJSON,
+		Log:         true,
+		Output:      out,
+		Observers: []Observer{observerFunc(func(c *fiber.Ctx, e *Event) {
+			observed++
+		})},
+	}))
+	app.Get("/", func(c *fiber.Ctx) {
+		c.SendString("OK")
+	})
+	app.Get("/health", func(c *fiber.Ctx) {
+		c.SendString("OK")
+	})
+	app.All("/admin/maintenance", m.Handler())
+
+	get := func(method, target string) (int, string, string) {
+		req := httptest.NewRequest(method, target, nil)
+		resp, err := app.Test(req)
+		if !assert.NoError(t, err) {
+			return 0, "", ""
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, resp.Header.Get("Retry-After"), string(body)
+	}
+
+	status, _, body := get("GET", "/")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "OK", body)
+
+	status, _, body = get("POST", "/admin/maintenance")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.JSONEq(t, `{"enabled":true}`, body)
+	assert.True(t, m.Enabled())
+
+	status, retryAfter, body := get("GET", "/")
+	assert.Equal(t, fiber.StatusServiceUnavailable, status)
+	assert.Equal(t, "120", retryAfter)
+	b := make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal([]byte(body), &b))
+	assert.Equal(t, "Service Unavailable", b["message"])
+	assert.Equal(t, "MAINTENANCE", b["code"])
+
+	// planned unavailability is neither logged nor observed
+	assert.Empty(t, out.String())
+	assert.Equal(t, 0, observed)
+
+	status, _, _ = get("GET", "/health")
+	assert.Equal(t, fiber.StatusOK, status)
+
+	status, _, body = get("DELETE", "/admin/maintenance")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.JSONEq(t, `{"enabled":false}`, body)
+
+	status, _, _ = get("GET", "/")
+	assert.Equal(t, fiber.StatusOK, status)
+}
+
+func TestMaintenance_sources(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "maintenance")
+	m, err := NewMaintenance(MaintenanceConfig{
+		File:              file,
+		FileCheckInterval: 20 * time.Millisecond,
+		Env:               "ERRHANDLER_TEST_MAINTENANCE",
+	})
+	assert.NoError(t, err)
+	assert.False(t, m.Enabled())
+
+	// the file check is cached
+	assert.NoError(t, ioutil.WriteFile(file, nil, 0644))
+	assert.False(t, m.Enabled())
+	time.Sleep(30 * time.Millisecond)
+	assert.True(t, m.Enabled())
+	assert.NoError(t, os.Remove(file))
+	time.Sleep(30 * time.Millisecond)
+	assert.False(t, m.Enabled())
+
+	t.Setenv("ERRHANDLER_TEST_MAINTENANCE", "true")
+	assert.True(t, m.Enabled())
+	t.Setenv("ERRHANDLER_TEST_MAINTENANCE", "0")
+	assert.False(t, m.Enabled())
+}
+
+func TestMaintenance_allow_ips(t *testing.T) {
+	for _, tc := range []struct {
+		allow    []string
+		expected int
+	}{
+		{[]string{"0.0.0.0"}, fiber.StatusOK},
+		{[]string{"0.0.0.0/8"}, fiber.StatusOK},
+		{[]string{"10.0.0.1", "::1"}, fiber.StatusServiceUnavailable},
+	} {
+		m, err := NewMaintenance(MaintenanceConfig{AllowIPs: tc.allow})
+		assert.NoError(t, err)
+		m.Enable()
+		app := fiber.New()
+		app.Use(New(Config{Maintenance: m}))
+		app.Get("/", func(c *fiber.Ctx) {
+			c.SendString("OK")
+		})
+
+		req := httptest.NewRequest("GET", "/", nil)
+		if resp, err := app.Test(req); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, tc.expected, resp.StatusCode)
+		}
+	}
+}
+
+func TestNewMaintenance_invalid_ip(t *testing.T) {
+	_, err := NewMaintenance(MaintenanceConfig{AllowIPs: []string{"10.0.0.1", "10.0.0.300"}})
+	assert.EqualError(t, err, `invalid allowed IP "10.0.0.300": invalid CIDR address: 10.0.0.300/32`)
+}
@@ -0,0 +1,184 @@
+package fiber_errhandler
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gofiber/fiber"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MetricsConfig ...
+type MetricsConfig struct {
+	// Namespace is prepended to the metric names
+	// Optional. Default: "errhandler"
+	Namespace string
+	// Buckets of the handling time histogram, in seconds
+	// Optional. Default: DefaultBuckets
+	Buckets []float64
+	// FingerprintLabel adds the fingerprint label to the error counter.
+	// Every distinct failure creates a series, see Fingerprints for a bounded view of fingerprints.
+	// Optional. Default: false
+	FingerprintLabel bool
+}
+
+// DefaultBuckets of the handling time histogram, in seconds
+var DefaultBuckets = []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1}
+
+// Labels of the error counter
+type errorLabels struct {
+	status int
+	code   string
+	route  string
+	method string
+	panic  bool
+	// fingerprint of the error, see Event.Fingerprint
+	fingerprint string
+}
+
+// Labels of the handling time histogram
+type durationLabels struct {
+	route  string
+	method string
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Metrics counts the handled errors and exposes them in Prometheus text exposition format.
+// Add it to `Config.Observers` and mount Metrics.Handler, e.g.
+//
+//	metrics := errhandler.NewMetrics()
+//	app.Use(errhandler.New(errhandler.Config{Observers: []errhandler.Observer{metrics}}))
+//	app.Get("/metrics", metrics.Handler())
+type Metrics struct {
+	namespace   string
+	buckets     []float64
+	fingerprint bool
+
+	mu        sync.Mutex
+	errors    map[errorLabels]uint64
+	durations map[durationLabels]*histogram
+}
+
+// NewMetrics ...
+func NewMetrics(config ...MetricsConfig) *Metrics {
+	var cfg MetricsConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Namespace == "" {
+		cfg.Namespace = "errhandler"
+	}
+	if cfg.Buckets == nil {
+		cfg.Buckets = DefaultBuckets
+	}
+	buckets := append([]float64(nil), cfg.Buckets...)
+	sort.Float64s(buckets)
+
+	return &Metrics{
+		namespace:   cfg.Namespace,
+		buckets:     buckets,
+		fingerprint: cfg.FingerprintLabel,
+		errors:      make(map[errorLabels]uint64),
+		durations:   make(map[durationLabels]*histogram),
+	}
+}
+
+// Observe implements Observer
+func (m *Metrics) Observe(c *fiber.Ctx, e *Event) {
+	el := errorLabels{
+		status: e.StatusCode,
+		code:   e.Code,
+		route:  e.Route,
+		method: e.Method,
+		panic:  e.Panic,
+	}
+	if m.fingerprint {
+		el.fingerprint = e.Fingerprint
+	}
+	dl := durationLabels{
+		route:  e.Route,
+		method: e.Method,
+	}
+	seconds := e.Duration.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.errors[el]++
+	h, ok := m.durations[dl]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.durations[dl] = h
+	}
+	for i, le := range m.buckets {
+		if seconds <= le {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// Write the metrics in Prometheus text exposition format
+func (m *Metrics) writeTo(buf *bytes.Buffer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name := m.namespace + "_errors_total"
+	fmt.Fprintf(buf, "# HELP %s Number of errors handled.\n# TYPE %s counter\n", name, name)
+	lines := make([]string, 0, len(m.errors))
+	for l, v := range m.errors {
+		labels := fmt.Sprintf("status=%s,code=%s,route=%s,method=%s,panic=%s",
+			quoteLabel(strconv.Itoa(l.status)), quoteLabel(l.code), quoteLabel(l.route), quoteLabel(l.method),
+			quoteLabel(strconv.FormatBool(l.panic)))
+		if m.fingerprint {
+			labels += ",fingerprint=" + quoteLabel(l.fingerprint)
+		}
+		lines = append(lines, fmt.Sprintf("%s{%s} %d\n", name, labels, v))
+	}
+	sort.Strings(lines)
+	buf.WriteString(strings.Join(lines, ""))
+
+	name = m.namespace + "_handling_duration_seconds"
+	fmt.Fprintf(buf, "# HELP %s Time spent handling errors.\n# TYPE %s histogram\n", name, name)
+	lines = lines[:0]
+	for l, h := range m.durations {
+		labels := fmt.Sprintf("route=%s,method=%s", quoteLabel(l.route), quoteLabel(l.method))
+		var b strings.Builder
+		for i, le := range m.buckets {
+			fmt.Fprintf(&b, "%s_bucket{%s,le=%s} %d\n", name, labels,
+				quoteLabel(strconv.FormatFloat(le, 'g', -1, 64)), h.counts[i])
+		}
+		fmt.Fprintf(&b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+		fmt.Fprintf(&b, "%s_sum{%s} %s\n", name, labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "%s_count{%s} %d\n", name, labels, h.count)
+		lines = append(lines, b.String())
+	}
+	sort.Strings(lines)
+	buf.WriteString(strings.Join(lines, ""))
+}
+
+// Handler returns a handler which exposes the metrics in Prometheus text exposition format
+func (m *Metrics) Handler() func(*fiber.Ctx) {
+	return func(c *fiber.Ctx) {
+		buf := &bytes.Buffer{}
+		m.writeTo(buf)
+		c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+		c.SendBytes(buf.Bytes())
+	}
+}
+
+// Quote and escape a label value
+func quoteLabel(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, "\n", `\n`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	return `"` + v + `"`
+}
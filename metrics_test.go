@@ -0,0 +1,83 @@
+package fiber_errhandler
+
+import (
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics(MetricsConfig{
+		Buckets: []float64{1},
+	})
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Observers: []Observer{metrics},
+	}))
+	app.Get("/metrics", metrics.Handler())
+	app.Get("/users/:id", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusNotFound, "User not found", nil).WithCode("USER_NOT_FOUND"))
+	})
+	app.Get("/panic", func(c *fiber.Ctx) {
+		panic("i'm panic")
+	})
+
+	for _, path := range []string{"/users/1", "/users/2", "/panic"} {
+		if _, err := app.Test(httptest.NewRequest("GET", path, nil)); err != nil {
+			assert.NoError(t, err)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.True(t, strings.HasPrefix(resp.Header.Get(fiber.HeaderContentType), "text/plain; version=0.0.4"))
+
+		if b, err := ioutil.ReadAll(resp.Body); err != nil {
+			assert.NoError(t, err)
+		} else {
+			body := string(b)
+			assert.Contains(t, body, "# TYPE errhandler_errors_total counter\n")
+			assert.Contains(t, body, `errhandler_errors_total{status="404",code="USER_NOT_FOUND",route="/users/:id",method="GET",panic="false"} 2`+"\n")
+			assert.Contains(t, body, `errhandler_errors_total{status="500",code="",route="/panic",method="GET",panic="true"} 1`+"\n")
+			assert.Contains(t, body, "# TYPE errhandler_handling_duration_seconds histogram\n")
+			assert.Contains(t, body, `errhandler_handling_duration_seconds_bucket{route="/users/:id",method="GET",le="1"} 2`+"\n")
+			assert.Contains(t, body, `errhandler_handling_duration_seconds_bucket{route="/users/:id",method="GET",le="+Inf"} 2`+"\n")
+			assert.Contains(t, body, `errhandler_handling_duration_seconds_count{route="/panic",method="GET"} 1`+"\n")
+		}
+	}
+}
+
+func TestMetrics_fingerprintLabel(t *testing.T) {
+	metrics := NewMetrics(MetricsConfig{
+		FingerprintLabel: true,
+	})
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Observers: []Observer{metrics},
+	}))
+	app.Get("/metrics", metrics.Handler())
+	app.Get("/users/:id", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusNotFound, "User not found", nil).WithCode("USER_NOT_FOUND"))
+	})
+
+	if _, err := app.Test(httptest.NewRequest("GET", "/users/1", nil)); err != nil {
+		assert.NoError(t, err)
+	}
+
+	fp := fingerprint(NewHttpError(fiber.StatusNotFound, "User not found", nil).WithCode("USER_NOT_FOUND"), "USER_NOT_FOUND", nil)
+	if resp, err := app.Test(httptest.NewRequest("GET", "/metrics", nil)); err != nil {
+		assert.NoError(t, err)
+	} else if b, err := ioutil.ReadAll(resp.Body); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Contains(t, string(b), `errhandler_errors_total{status="404",code="USER_NOT_FOUND",route="/users/:id",method="GET",panic="false",fingerprint="`+fp+`"} 1`+"\n")
+	}
+}
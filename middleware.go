@@ -1,12 +1,25 @@
 package fiber_errhandler
 
 import (
-	"fmt"
 	"github.com/gofiber/fiber"
 	"io"
 	"os"
 	"strconv"
 	"strings"
+	"text/template"
+	"time"
+)
+
+// Negotiation defines which request headers decide the content type of the error response
+type Negotiation int
+
+const (
+	// Use `Content-Type` header, fallback to `Accept` header if it's empty or not supported
+	NegotiateContentTypeThenAccept Negotiation = iota
+	// Use `Accept` header, fallback to `Content-Type` header if it's empty or not supported
+	NegotiateAcceptThenContentType
+	// Use `Accept` header only
+	NegotiateAcceptOnly
 )
 
 // Config ...
@@ -17,19 +30,88 @@ type Config struct {
 	// Custom error handler
 	// Optional. Default: nil
 	Handler func(*fiber.Ctx, error, func(...interface{}))
+	// Transformers are applied in order to the error before it is logged and passed to Handler.
+	// Optional. Default: nil
+	Transformers []Transformer
 	// Log all errors to output
 	// Optional. Default: false
 	Log bool
 	// Output is a writer where logs are written
 	// Default: os.Stderr
 	Output io.Writer
+	// LogLimiter rate-limits and samples the logs, it is safe to share between middlewares
+	// Optional. Default: nil
+	LogLimiter *LogLimiter
 	// Use c.Render for content-type html
 	// Optional. Default: false
 	UseTemplate bool
+	// ContentType forces the content type used to render errors, skipping negotiation.
+	// Only accept `text/plain`, `application/json`, `application/vnd.api+json`, `text/html`, `application/msgpack` or `application/cbor`
+	// Optional. Default: ""
+	ContentType string
+	// FormatQuery is the query parameter used to override the response format, e.g. `?format=json`
+	// Optional. Default: ""
+	FormatQuery string
+	// FormatExtension allows the path extension to override the response format, e.g. `/users/1.json`
+	// Optional. Default: false
+	FormatExtension bool
+	// Formats is the allow-list of formats which can be requested with FormatQuery or FormatExtension.
+	// Known formats are `json`, `jsonapi`, `html`, `htm`, `txt`, `text`, `msgpack` and `cbor`
+	// Optional. Default: ["json", "html", "txt"]
+	Formats []string
+	// Negotiation defines which request headers decide the content type of the error response
+	// Optional. Default: NegotiateContentTypeThenAccept
+	Negotiation Negotiation
+	// DefaultContentType is used when any content type is accepted (`*/*`) or none of the requested ones is supported
+	// Optional. Default: "text/plain"
+	DefaultContentType string
+	// Reset clears the body and the response headers set by the failed handler before rendering the error.
+	// Streamed responses and hijacked connections are never reset.
+	// Optional. Default: false
+	Reset bool
+	// KeepHeaders is the allow-list of response headers kept when Reset is enabled
+	// Optional. Default: DefaultKeepHeaders
+	KeepHeaders []string
+	// Observers are notified of every handled error, e.g. Metrics
+	// Optional. Default: nil
+	Observers []Observer
+	// TraceID returns the trace ID injected into JSON bodies and template data, e.g. the TraceID method of Tracing in the otel subpackage
+	// Optional. Default: nil
+	TraceID func(*fiber.Ctx) string
+	// Catalog resolves the messages of errors implementing Localizable in the request locale
+	// Optional. Default: nil
+	Catalog *Catalog
+	// LocaleQuery is the query parameter overriding `Accept-Language`, e.g. `?lang=fr`
+	// Optional. Default: ""
+	LocaleQuery string
+	// LocaleCookie is the cookie overriding `Accept-Language`
+	// Optional. Default: ""
+	LocaleCookie string
+	// GraphQL renders errors as GraphQL responses, usually enabled for the GraphQL route with Override
+	// Optional. Default: false
+	GraphQL bool
+	// JSONRPC renders errors as JSON-RPC 2.0 responses, usually enabled for the JSON-RPC route with Override
+	// Optional. Default: false
+	JSONRPC bool
+	// GoogleAPI renders errors in the Google API JSON error format, see ToStatus
+	// Optional. Default: false
+	GoogleAPI bool
+	// TextTemplate renders plain-text errors, see DefaultTextTemplate and TextError
+	// Optional. Default: nil, only the message is sent
+	TextTemplate *template.Template
+	// TextData adds the error data as `key=value` lines to the TextTemplate data
+	// Optional. Default: false
+	TextData bool
+	// Maintenance short-circuits requests with a 503 error while maintenance mode is on, it cannot be overridden per route
+	// Optional. Default: nil
+	Maintenance *Maintenance
+	// PanicPolicy defines what to do with recovered panics, panics with `http.ErrAbortHandler` always abort the connection
+	// Optional. Default: PanicRecover
+	PanicPolicy PanicPolicy
 }
 
-// Send error message as JSON
-func handleJSON(c *fiber.Ctx, args ...interface{}) {
+// Convert the handler args to the HTTPError to be sent as structured data (JSON and alike)
+func getHTTPError(args ...interface{}) HTTPError {
 	l := len(args)
 	var httpErr HTTPError
 
@@ -45,27 +127,41 @@ func handleJSON(c *fiber.Ctx, args ...interface{}) {
 			httpErr = NewHttpError(fiber.StatusInternalServerError, "Internal Server Error", args[0])
 		}
 	}
+	return httpErr
+}
+
+// Send error message as JSON
+func handleJSON(c *fiber.Ctx, cfg Config, args ...interface{}) {
+	httpErr := getHTTPError(args...)
 
 	c.Status(httpErr.StatusCode())
+	c.JSON(errorBody(c, cfg, httpErr))
+}
 
+// Structure of the error sent as JSON, MessagePack or CBOR
+func errorBody(c *fiber.Ctx, cfg Config, httpErr HTTPError) fiber.Map {
+	body := fiber.Map{
+		"message": httpErr.Message(),
+	}
 	if httpErr.Data() != nil {
-		c.JSON(fiber.Map{
-			"message": httpErr.Message(),
-			"error":    httpErr.Data(),
-		})
-	} else {
-		c.JSON(fiber.Map{
-			"message": httpErr.Message(),
-		})
+		body["error"] = httpErr.Data()
+	}
+	if code := errorCode(httpErr); code != "" {
+		body["code"] = code
+	}
+	if traceID := getTraceID(c, cfg); traceID != "" {
+		body["traceId"] = traceID
 	}
+	addRetryFields(body, httpErr)
+	return body
 }
 
 // Render template based on args
 // Posible args combinations are:
-// handleTemplate(*fiber.Ctx, string)
-// handleTemplate(*fiber.Ctx, string, error)
-// handleTemplate(*fiber.Ctx, error)
-func handleTemplate(c *fiber.Ctx, args ...interface{}) {
+// handleTemplate(*fiber.Ctx, Config, string)
+// handleTemplate(*fiber.Ctx, Config, string, error)
+// handleTemplate(*fiber.Ctx, Config, error)
+func handleTemplate(c *fiber.Ctx, cfg Config, args ...interface{}) {
 	l := len(args)
 	var httpErr HTTPError = NewHttpError(fiber.StatusInternalServerError, "Internal Server Error", nil)
 	var view = "500"
@@ -95,12 +191,14 @@ func handleTemplate(c *fiber.Ctx, args ...interface{}) {
 	}
 
 	c.Status(httpErr.StatusCode()).Render(view, fiber.Map{
-		"error": httpErr,
+		"error":   httpErr,
+		"traceId": getTraceID(c, cfg),
+		"locale":  getLocale(c, cfg),
 	})
 }
 
 // Send error message as plain text
-func handlePlainText(c *fiber.Ctx, args ...interface{}) {
+func handlePlainText(c *fiber.Ctx, cfg Config, args ...interface{}) {
 	l := len(args)
 
 	if l > 0 {
@@ -119,40 +217,223 @@ func handlePlainText(c *fiber.Ctx, args ...interface{}) {
 	c.SendStatus(fiber.StatusInternalServerError)
 }
 
-// Decide the content type to be used based on `Content-Type` or `Accept` header.
-// Only accept `text/plain`, `*/json`, `*/html` or `*/xhtml-xml`
-// If `Accept` header contains multiple values, the first to come up will be used with `text/plain` as fallback value.
-func getPreferedContentType(c *fiber.Ctx) (ct string) {
-	// default text/plain
-	ct = fiber.MIMETextPlain
-	header := strings.ToLower(c.Get(fiber.HeaderContentType))
-	if header != "" {
-		if factorSign := strings.IndexByte(header, ';'); factorSign != -1 {
-			ct = header[:factorSign]
+// Get the trace ID of the request, empty string if not traced
+func getTraceID(c *fiber.Ctx, cfg Config) string {
+	if cfg.TraceID == nil {
+		return ""
+	}
+	return cfg.TraceID(c)
+}
+
+// Map a media type to the content type used to render errors.
+// Only accept `text/plain`, `application/vnd.api+json`, `*/json`, `*/html`, `*/xhtml+xml`, `*/msgpack` or `*/cbor`, `*/*` maps to `def`.
+// Return empty string if the media type is not supported.
+func matchContentType(val string, def string) string {
+	if factorSign := strings.IndexByte(val, ';'); factorSign != -1 {
+		val = val[:factorSign]
+	}
+	val = strings.TrimSpace(val)
+
+	if val == fiber.MIMETextPlain {
+		return fiber.MIMETextPlain
+	} else if val == MIMEApplicationJSONAPI {
+		return MIMEApplicationJSONAPI
+	} else if strings.HasSuffix(val, "json") {
+		return fiber.MIMEApplicationJSON
+	} else if strings.HasSuffix(val, "html") || strings.HasSuffix(val, "xhtml+xml") {
+		return fiber.MIMETextHTML
+	} else if strings.HasSuffix(val, "msgpack") {
+		return MIMEApplicationMsgpack
+	} else if strings.HasSuffix(val, "cbor") {
+		return MIMEApplicationCBOR
+	} else if val == "*/*" {
+		return def
+	}
+	return ""
+}
+
+// Decide the content type based on `Content-Type` header
+func contentTypeFromContentType(c *fiber.Ctx, def string) string {
+	return matchContentType(strings.ToLower(c.Get(fiber.HeaderContentType)), def)
+}
+
+// Decide the content type based on `Accept` header.
+// If `Accept` header contains multiple values, the first supported one will be used.
+func contentTypeFromAccept(c *fiber.Ctx, def string) string {
+	header := strings.ToLower(c.Get(fiber.HeaderAccept))
+	for _, val := range strings.Split(header, ",") {
+		if ct := matchContentType(val, def); ct != "" {
+			return ct
+		}
+	}
+	return ""
+}
+
+// Decide the content type to be used based on `Content-Type` or `Accept` header, in the order defined by `cfg.Negotiation`.
+// `cfg.DefaultContentType` is used as fallback value.
+func getPreferedContentType(c *fiber.Ctx, cfg Config) (ct string) {
+	def := cfg.DefaultContentType
+	if def == "" {
+		def = fiber.MIMETextPlain
+	}
+
+	switch cfg.Negotiation {
+	case NegotiateAcceptOnly:
+		ct = contentTypeFromAccept(c, def)
+	case NegotiateAcceptThenContentType:
+		if ct = contentTypeFromAccept(c, def); ct == "" {
+			ct = contentTypeFromContentType(c, def)
+		}
+	default:
+		if ct = contentTypeFromContentType(c, def); ct == "" {
+			ct = contentTypeFromAccept(c, def)
+		}
+	}
+
+	if ct == "" {
+		ct = def
+	}
+	return
+}
+
+// Default handler, render the error based on the prefered content type
+func fallback(c *fiber.Ctx, cfg Config) func(...interface{}) {
+	return func(args ...interface{}) {
+		// Streaming clients cannot read a regular response
+		if webSocketUpgrade(c) {
+			handleWebSocket(c, cfg, args...)
 			return
 		}
-		ct = header
-	} else {
-		// if 'Content-Type' is empty then use 'Accept'
-		header = strings.ToLower(c.Get(fiber.HeaderAccept))
-		if factorSign := strings.IndexByte(header, ';'); factorSign != -1 {
-			header = header[:factorSign]
-		}
-		vals := strings.Split(header, ",")
-
-		for _, val := range vals {
-			if val == fiber.MIMETextPlain {
-				return
-			} else if strings.HasSuffix(val, "json") {
-				ct = fiber.MIMEApplicationJSON
-				return
-			} else if strings.HasSuffix(val, "html") || strings.HasSuffix(val, "xhtml+xml") {
-				ct = fiber.MIMETextHTML
-				return
+		if eventStream(c) {
+			handleSSE(c, cfg, args...)
+			return
+		}
+		// GraphQL endpoints always respond with a GraphQL response
+		if cfg.GraphQL {
+			handleGraphQL(c, cfg, args...)
+			return
+		}
+		// JSON-RPC endpoints always respond with a JSON-RPC response
+		if cfg.JSONRPC {
+			handleJSONRPC(c, cfg, args...)
+			return
+		}
+		// gRPC-gateway style endpoints always respond in the Google API error format
+		if cfg.GoogleAPI {
+			handleGoogleAPI(c, cfg, args...)
+			return
+		}
+
+		// requested format takes precedence over the forced content type
+		ct := getRequestedContentType(c, cfg)
+		if ct == "" {
+			ct = cfg.ContentType
+		}
+		if ct == "" {
+			ct = getPreferedContentType(c, cfg)
+		}
+
+		if ct == fiber.MIMEApplicationJSON {
+			handleJSON(c, cfg, args...)
+			return
+		} else if ct == MIMEApplicationJSONAPI {
+			handleJSONAPI(c, cfg, args...)
+			return
+		} else if ct == MIMEApplicationMsgpack {
+			handleMsgpack(c, cfg, args...)
+			return
+		} else if ct == MIMEApplicationCBOR {
+			handleCBOR(c, cfg, args...)
+			return
+		} else if ct == fiber.MIMETextHTML {
+			// use template
+			if cfg.UseTemplate {
+				handleTemplate(c, cfg, args...)
+			} else {
+				// use json if template is not used
+				handleJSON(c, cfg, args...)
 			}
+			return
+		} else if cfg.TextTemplate != nil {
+			handleTextTemplate(c, cfg, args...)
+			return
+		} else {
+			handlePlainText(c, cfg, args...)
+			return
 		}
 	}
-	return
+}
+
+// Transform, log and handle the error using the route config
+// stack is the stack of the panic, nil if err was passed to c.Next
+func handleError(c *fiber.Ctx, cfg Config, err error, stack []uintptr) {
+	start := time.Now()
+	// Aborted handlers are not errors
+	if stack != nil && abortPanic(err) {
+		abortConnection(c)
+		return
+	}
+	cfg = routeConfig(c, cfg)
+	if stack != nil {
+		recordPanic(c)
+	}
+	recovered := panicValue(err)
+	err = transform(c, err, cfg.Transformers)
+	ev := newEvent(c, err, stack, start)
+	defer notify(c, cfg, ev)
+	if stack != nil && cfg.PanicPolicy != PanicRecover {
+		ev.Aborted = true
+		ev.StatusCode = errorStatus(err)
+		if cfg.Log {
+			writeLog(cfg, ev, logLine(ev))
+		}
+		if cfg.PanicPolicy == PanicRepanic {
+			// observers are notified while panicking
+			panic(recovered)
+		}
+		abortConnection(c)
+		return
+	}
+	// Events already written are kept, the error is appended as another event
+	sseStarted := eventStreamStarted(c)
+	// Clear what the failed handler set, streams and hijacked connections cannot be reset
+	if cfg.Reset && !responseStreamed(c) && !sseStarted {
+		resetResponse(c, cfg)
+	}
+	// Never render over a response the handler already started
+	if responseStarted(c) && !sseStarted {
+		ev.StatusCode = errorStatus(err)
+		abortResponse(c, cfg, ev)
+		return
+	}
+	// Log error
+	if cfg.Log {
+		writeLog(cfg, ev, logLine(ev))
+	}
+	// Logs and observers get the original message
+	render(c, cfg, err)
+	// Started event streams keep their status and WebSocket errors are sent after a 101,
+	// observers get the status of the error
+	if sseStarted || c.Fasthttp.Response.StatusCode() == fiber.StatusSwitchingProtocols {
+		ev.StatusCode = errorStatus(err)
+	}
+}
+
+// Render the localised error with the custom handler or the default one
+func render(c *fiber.Ctx, cfg Config, err error) {
+	err = localize(c, cfg, err)
+	setRetryAfter(c, err)
+	if cfg.Handler != nil {
+		cfg.Handler(c, err, fallback(c, cfg))
+	} else {
+		fallback(c, cfg)(err)
+	}
+}
+
+// Render a planned 503, e.g. during maintenance, which is not a failure of the application:
+// it is neither logged nor passed to observers, so it does not reach reporting, notifiers or metrics.
+func handleUnavailable(c *fiber.Ctx, cfg Config, err error) {
+	render(c, cfg, transform(c, err, cfg.Transformers))
 }
 
 // New ...
@@ -169,61 +450,29 @@ func New(config ...Config) func(*fiber.Ctx) {
 
 	// Return middleware handler
 	return func(c *fiber.Ctx) {
-		// default handler
-		errHandler := func(args ...interface{}) {
-			ct := getPreferedContentType(c)
-
-			if ct == fiber.MIMEApplicationJSON {
-				handleJSON(c, args...)
-				return
-			} else if ct == fiber.MIMETextHTML {
-				// use template
-				if cfg.UseTemplate {
-					handleTemplate(c, args...)
-				} else {
-					// use json if template is not used
-					handleJSON(c, args...)
-				}
-				return
-			} else {
-				handlePlainText(c, args...)
-				return
-			}
-		}
-
 		// Filter request to skip middleware
 		if cfg.Filter != nil && cfg.Filter(c) {
 			c.Next()
 			return
 		}
+		// Requests are not served during maintenance, the error is rendered as any other but not observed
+		if cfg.Maintenance != nil && cfg.Maintenance.active(c) {
+			handleUnavailable(c, cfg, cfg.Maintenance.err())
+			return
+		}
 		defer func() {
 			if r := recover(); r != nil {
-				err, ok := r.(error)
-				if !ok {
-					err = fmt.Errorf("%v", r)
-				}
-				// Log error
-				if cfg.Log {
-					cfg.Output.Write([]byte(err.Error() + "\n"))
-				}
-				if cfg.Handler != nil {
-					cfg.Handler(c, err, errHandler)
-				} else {
-					errHandler(err)
-				}
+				handleError(c, cfg, panicError(r), callers())
 			}
 		}()
 		c.Next()
+		// Routes which keep panicking are not served during the cooldown, see PanicBreaker.Guard
+		if err := breakerErr(c); err != nil {
+			handleUnavailable(c, routeConfig(c, cfg), err)
+			return
+		}
 		if c.Error() != nil {
-			if cfg.Log {
-				cfg.Output.Write([]byte(c.Error().Error() + "\n"))
-			}
-
-			if cfg.Handler != nil {
-				cfg.Handler(c, c.Error(), errHandler)
-			} else {
-				errHandler(c.Error())
-			}
+			handleError(c, cfg, c.Error(), nil)
 		}
 	}
 }
@@ -1,6 +1,7 @@
 package fiber_errhandler
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -291,6 +292,255 @@ func TestErrHandler_custom_handler(t *testing.T) {
 	}
 }
 
+func TestErrHandler_transformers(t *testing.T) {
+	errNotFound := errors.New("record not found")
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Transformers: []Transformer{
+			MapError(errNotFound, NewHttpError(fiber.StatusNotFound, "User not found", nil)),
+			func(c *fiber.Ctx, err error) error {
+				if he, ok := err.(HTTPError); ok && he.StatusCode() == fiber.StatusNotFound {
+					return NewHttpError(he.StatusCode(), he.Message(), he.Data()).WithCode("USER_NOT_FOUND")
+				}
+				return nil
+			},
+		},
+	}))
+	app.Get("/user", func(c *fiber.Ctx) {
+		c.Next(fmt.Errorf("find user: %w", errNotFound))
+	})
+	app.Get("/err", func(c *fiber.Ctx) {
+		c.Next(errors.New("bad thing happens"))
+	})
+
+	req := httptest.NewRequest("GET", "/user", nil)
+	req.Header.Set("Accept", "application/json")
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		b := make(map[string]interface{})
+		if err := json.NewDecoder(resp.Body).Decode(&b); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, map[string]interface{}{
+				"message": "User not found",
+				"code":    "USER_NOT_FOUND",
+			}, b)
+		}
+	}
+
+	req = httptest.NewRequest("GET", "/err", nil)
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+
+		if b, err := ioutil.ReadAll(resp.Body); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, "bad thing happens", string(b))
+		}
+	}
+}
+
+func TestErrHandler_format_override(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(Config{
+		FormatQuery:     "format",
+		FormatExtension: true,
+		Formats:         []string{"json", "txt"},
+	}))
+	app.Get("/400", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusBadRequest, "Bad request", nil))
+	})
+	app.Get("/users/:id", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusNotFound, "User not found", nil))
+	})
+
+	req := httptest.NewRequest("GET", "/400?format=json", nil)
+	req.Header.Set("Accept", fiber.MIMETextPlain)
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+		if b, err := ioutil.ReadAll(resp.Body); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, `{"message":"Bad request"}`, string(b))
+		}
+	}
+
+	req = httptest.NewRequest("GET", "/users/1.json", nil)
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+		if b, err := ioutil.ReadAll(resp.Body); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, `{"message":"User not found"}`, string(b))
+		}
+	}
+
+	// html is not allowed, fallback to negotiation
+	req = httptest.NewRequest("GET", "/400?format=html", nil)
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+		if b, err := ioutil.ReadAll(resp.Body); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, "Bad request", string(b))
+		}
+	}
+}
+
+func TestErrHandler_negotiation(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(Config{
+		Negotiation:        NegotiateAcceptOnly,
+		DefaultContentType: fiber.MIMEApplicationJSON,
+	}))
+	app.Post("/400", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusBadRequest, "Bad request", nil))
+	})
+
+	req := httptest.NewRequest("POST", "/400", nil)
+	req.Header.Set("Content-Type", fiber.MIMEApplicationForm)
+	req.Header.Set("Accept", "application/json")
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+		if b, err := ioutil.ReadAll(resp.Body); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, `{"message":"Bad request"}`, string(b))
+		}
+	}
+
+	req = httptest.NewRequest("POST", "/400", nil)
+	req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+	req.Header.Set("Accept", "text/plain;q=0.9, */*;q=0.8")
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+		if b, err := ioutil.ReadAll(resp.Body); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, "Bad request", string(b))
+		}
+	}
+
+	// */* maps to the default content type
+	req = httptest.NewRequest("POST", "/400", nil)
+	req.Header.Set("Accept", "*/*")
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+		if b, err := ioutil.ReadAll(resp.Body); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, `{"message":"Bad request"}`, string(b))
+		}
+	}
+}
+
+func TestErrHandler_response_started(t *testing.T) {
+	out := &bytes.Buffer{}
+	var observed int
+	app := fiber.New()
+	app.Use(New(Config{
+		Log:    true,
+		Output: out,
+		Observers: []Observer{observerFunc(func(c *fiber.Ctx, e *Event) {
+			observed = e.StatusCode
+		})},
+	}))
+	app.Get("/partial", func(c *fiber.Ctx) {
+		c.SendString("partial body")
+		c.Next(errors.New("bad thing happens"))
+	})
+
+	req := httptest.NewRequest("GET", "/partial", nil)
+	req.Header.Set("Accept", "application/json")
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.True(t, resp.Close)
+
+		if b, err := ioutil.ReadAll(resp.Body); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, "partial body", string(b))
+		}
+	}
+	assert.Regexp(t, `^bad thing happens \(response already started, fingerprint: [0-9a-f]{16}\)\n$`, out.String())
+	// observers get the status of the error, not the one of the partial response
+	assert.Equal(t, fiber.StatusInternalServerError, observed)
+}
+
+func TestErrHandler_response_started_no_log(t *testing.T) {
+	out := &bytes.Buffer{}
+	app := fiber.New()
+	app.Use(New(Config{
+		Output: out,
+	}))
+	app.Get("/partial", func(c *fiber.Ctx) {
+		c.SendString("partial body")
+		c.Next(errors.New("bad thing happens"))
+	})
+
+	if _, err := app.Test(httptest.NewRequest("GET", "/partial", nil)); err != nil {
+		assert.NoError(t, err)
+	}
+	assert.Empty(t, out.String())
+}
+
+func TestErrHandler_reset(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(Config{
+		Reset: true,
+	}))
+	app.Get("/partial", func(c *fiber.Ctx) {
+		c.Set(fiber.HeaderCacheControl, "max-age=3600")
+		c.Set(fiber.HeaderAccessControlAllowOrigin, "*")
+		c.Cookie(&fiber.Cookie{Name: "session", Value: "abc"})
+		c.Type("html").SendString("partial body")
+		c.Next(NewHttpError(fiber.StatusBadRequest, "Bad request", nil))
+	})
+
+	req := httptest.NewRequest("GET", "/partial", nil)
+	req.Header.Set("Accept", "application/json")
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, "", resp.Header.Get(fiber.HeaderCacheControl))
+		assert.Equal(t, "", resp.Header.Get(fiber.HeaderSetCookie))
+		assert.Equal(t, "*", resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+		assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get(fiber.HeaderContentType))
+
+		if b, err := ioutil.ReadAll(resp.Body); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, `{"message":"Bad request"}`, string(b))
+		}
+	}
+}
+
 var _benchmark_string_original_fiber int
 func Benchmark_string_original_fiber(b *testing.B) {
 	//fmt.Println("Benchmark original fiber SendString")
@@ -435,4 +685,53 @@ func Benchmark_errhandler_json_err(b *testing.B) {
 		}
 	}
 	_benchmark_errhandler_json_err = _statusCode
-}
\ No newline at end of file
+}
+var _benchmark_errhandler_msgpack_err int
+func Benchmark_errhandler_msgpack_err(b *testing.B) {
+	app := fiber.New()
+	app.Use(New())
+	app.Get("/hello", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusBadRequest, "Bad request", fiber.Map{
+			"message": "Hello World",
+		}))
+	})
+
+	var _statusCode int
+
+	for n := 0; n < b.N; n++ {
+		req := httptest.NewRequest("GET", "/hello", nil)
+		req.Header.Set("Accept", MIMEApplicationMsgpack)
+		if resp, err := app.Test(req); err != nil {
+			assert.NoError(b, err)
+		} else {
+			assert.Equal(b, fiber.StatusBadRequest, resp.StatusCode)
+			_statusCode = resp.StatusCode
+		}
+	}
+	_benchmark_errhandler_msgpack_err = _statusCode
+}
+
+var _benchmark_errhandler_cbor_err int
+func Benchmark_errhandler_cbor_err(b *testing.B) {
+	app := fiber.New()
+	app.Use(New())
+	app.Get("/hello", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusBadRequest, "Bad request", fiber.Map{
+			"message": "Hello World",
+		}))
+	})
+
+	var _statusCode int
+
+	for n := 0; n < b.N; n++ {
+		req := httptest.NewRequest("GET", "/hello", nil)
+		req.Header.Set("Accept", MIMEApplicationCBOR)
+		if resp, err := app.Test(req); err != nil {
+			assert.NoError(b, err)
+		} else {
+			assert.Equal(b, fiber.StatusBadRequest, resp.StatusCode)
+			_statusCode = resp.StatusCode
+		}
+	}
+	_benchmark_errhandler_cbor_err = _statusCode
+}
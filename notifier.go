@@ -0,0 +1,197 @@
+package fiber_errhandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber"
+	"io"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Webhook payload templates, the `json` function encodes a value as JSON
+const (
+	// SlackTemplate is compatible with Slack incoming webhooks
+	SlackTemplate = `{"text": {{json (printf "Error burst: %d errors in %s on %s %s (status %d%s, fingerprint %s): %s" .Count .Window .Method .Route .StatusCode (or (and .Code (printf ", code %s" .Code)) "") .Fingerprint .Message)}}}`
+	// TeamsTemplate is compatible with Microsoft Teams incoming webhooks
+	TeamsTemplate = `{"@type": "MessageCard", "@context": "http://schema.org/extensions", "summary": "Error burst", "title": {{json (printf "Error burst on %s %s" .Method .Route)}}, "text": {{json (printf "%d errors in %s (status %d%s, fingerprint %s): %s" .Count .Window .StatusCode (or (and .Code (printf ", code %s" .Code)) "") .Fingerprint .Message)}}}`
+)
+
+// NotifierConfig ...
+type NotifierConfig struct {
+	// URL of the webhook
+	// Required.
+	URL string
+	// Threshold is the number of errors with the same fingerprint within Window which triggers a notification
+	// Optional. Default: 10
+	Threshold int
+	// Window in which errors are counted
+	// Optional. Default: 1 minute
+	Window time.Duration
+	// Cooldown is the minimum time between two notifications for the same fingerprint
+	// Optional. Default: 10 minutes
+	Cooldown time.Duration
+	// Template of the JSON payload, executed with a NotifierBurst
+	// Optional. Default: SlackTemplate
+	Template string
+	// Filter decides which errors are counted
+	// Optional. Default: nil
+	Filter func(*Event) bool
+	// Client used to post the payloads
+	// Optional. Default: http.Client with a 10s timeout
+	Client *http.Client
+	// Output is a writer where failed notifications are logged
+	// Optional. Default: nil
+	Output io.Writer
+}
+
+// NotifierBurst is the data of the payload template
+type NotifierBurst struct {
+	Fingerprint string
+	Count       int
+	Window      time.Duration
+	Method      string
+	Route       string
+	StatusCode  int
+	Code        string
+	Message     string
+}
+
+// Number of counters above which idle counters are removed
+const pruneCounters = 1024
+
+type burstCounter struct {
+	windowStart   time.Time
+	count         int
+	cooldownUntil time.Time
+}
+
+// Notifier is an Observer posting to a webhook when errors with the same fingerprint exceed a threshold within a window.
+// Like any Observer it is notified of every handled error once the response is rendered, regardless of `Config.Log`,
+// hence errors suppressed by `Config.LogLimiter` or never logged are still counted.
+type Notifier struct {
+	cfg  NotifierConfig
+	tmpl *template.Template
+
+	mu       sync.Mutex
+	counters map[string]*burstCounter
+	wg       sync.WaitGroup
+}
+
+// NewNotifier parses the payload template
+func NewNotifier(config NotifierConfig) (*Notifier, error) {
+	cfg := config
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = 10
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 10 * time.Minute
+	}
+	if cfg.Template == "" {
+		cfg.Template = SlackTemplate
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	tmpl, err := template.New("notifier").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(cfg.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Notifier{
+		cfg:      cfg,
+		tmpl:     tmpl,
+		counters: make(map[string]*burstCounter),
+	}, nil
+}
+
+// Observe implements Observer
+func (n *Notifier) Observe(c *fiber.Ctx, e *Event) {
+	if n.cfg.Filter != nil && !n.cfg.Filter(e) {
+		return
+	}
+
+	n.mu.Lock()
+	now := e.Time
+	counter, ok := n.counters[e.Fingerprint]
+	if !ok {
+		if len(n.counters) >= pruneCounters {
+			n.prune(now)
+		}
+		counter = &burstCounter{windowStart: now}
+		n.counters[e.Fingerprint] = counter
+	}
+	if now.Sub(counter.windowStart) > n.cfg.Window {
+		counter.windowStart = now
+		counter.count = 0
+	}
+	counter.count++
+	if counter.count < n.cfg.Threshold || now.Before(counter.cooldownUntil) {
+		n.mu.Unlock()
+		return
+	}
+	burst := NotifierBurst{
+		Fingerprint: e.Fingerprint,
+		Count:       counter.count,
+		Window:      n.cfg.Window,
+		Method:      e.Method,
+		Route:       e.Route,
+		StatusCode:  e.StatusCode,
+		Code:        e.Code,
+		Message:     errorMessage(e.Err),
+	}
+	counter.cooldownUntil = now.Add(n.cfg.Cooldown)
+	counter.windowStart = now
+	counter.count = 0
+	n.mu.Unlock()
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		if err := n.send(burst); err != nil && n.cfg.Output != nil {
+			n.cfg.Output.Write([]byte("notifier: " + err.Error() + "\n"))
+		}
+	}()
+}
+
+// Remove the counters which are neither counting nor cooling down
+func (n *Notifier) prune(now time.Time) {
+	for fp, counter := range n.counters {
+		if now.Sub(counter.windowStart) > n.cfg.Window && now.After(counter.cooldownUntil) {
+			delete(n.counters, fp)
+		}
+	}
+}
+
+func (n *Notifier) send(burst NotifierBurst) error {
+	buf := &bytes.Buffer{}
+	if err := n.tmpl.Execute(buf, burst); err != nil {
+		return err
+	}
+	resp, err := n.cfg.Client.Post(n.cfg.URL, fiber.MIMEApplicationJSON, buf)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Wait for the pending notifications to be sent
+func (n *Notifier) Wait() {
+	n.wg.Wait()
+}
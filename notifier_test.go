@@ -0,0 +1,76 @@
+package fiber_errhandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNotifier(t *testing.T) {
+	var mu sync.Mutex
+	var payloads []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := make(map[string]interface{})
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		mu.Lock()
+		payloads = append(payloads, payload)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	notifier, err := NewNotifier(NotifierConfig{
+		URL:       server.URL,
+		Threshold: 3,
+		Window:    time.Minute,
+		Cooldown:  time.Hour,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Observers: []Observer{notifier},
+	}))
+	app.Get("/err", func(c *fiber.Ctx) {
+		c.Next(errors.New("bad thing happens"))
+	})
+
+	// 3 errors trigger the notification, the next ones are in cooldown
+	for i := 0; i < 7; i++ {
+		if _, err := app.Test(httptest.NewRequest("GET", "/err", nil)); err != nil {
+			assert.NoError(t, err)
+		}
+	}
+	notifier.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if assert.Len(t, payloads, 1) {
+		assert.Contains(t, payloads[0]["text"], "Error burst: 3 errors in 1m0s on GET /err (status 500, fingerprint ")
+		assert.Contains(t, payloads[0]["text"], "): bad thing happens")
+	}
+}
+
+func TestNotifier_teams_template(t *testing.T) {
+	notifier, err := NewNotifier(NotifierConfig{URL: "http://localhost", Template: TeamsTemplate})
+	if !assert.NoError(t, err) {
+		return
+	}
+	buf := &bytes.Buffer{}
+	assert.NoError(t, notifier.tmpl.Execute(buf, NotifierBurst{
+		Count:   3,
+		Route:   "/users/:id",
+		Code:    "USER_NOT_FOUND",
+		Message: `user "42" not found`,
+	}))
+	assert.True(t, json.Valid(buf.Bytes()))
+	assert.Contains(t, buf.String(), `code USER_NOT_FOUND`)
+}
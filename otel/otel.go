@@ -0,0 +1,100 @@
+// Package otel records the errors handled by fiber-errhandler on OpenTelemetry spans.
+package otel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber"
+	errhandler "github.com/hendratommy/fiber-errhandler"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+)
+
+// SpanContextKey is the c.Locals key of the context.Context holding the active span, set by the tracing middleware.
+// A trace.Span stored under this key is used as is.
+const SpanContextKey = "otel.context"
+
+// TracingConfig ...
+type TracingConfig struct {
+	// Span returns the active span of the request
+	// Optional. Default: the span stored under SpanContextKey
+	Span func(*fiber.Ctx) trace.Span
+}
+
+// Tracing is an Observer recording handled errors on the active trace span:
+// the span status is set to error for 5xx, an exception event is added with the error type, message and stack,
+// and the error code and status are attached as attributes.
+// Add it to `errhandler.Config.Observers` and set `errhandler.Config.TraceID` to Tracing.TraceID
+// to inject the trace ID into response bodies.
+type Tracing struct {
+	span func(*fiber.Ctx) trace.Span
+}
+
+// NewTracing ...
+func NewTracing(config ...TracingConfig) *Tracing {
+	var cfg TracingConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Span == nil {
+		cfg.Span = func(c *fiber.Ctx) trace.Span {
+			switch v := c.Locals(SpanContextKey).(type) {
+			case trace.Span:
+				return v
+			case context.Context:
+				return trace.SpanFromContext(v)
+			}
+			return trace.SpanFromContext(context.Background())
+		}
+	}
+	return &Tracing{span: cfg.Span}
+}
+
+// TraceID returns the trace ID of the active span, empty string if there is none
+func (t *Tracing) TraceID(c *fiber.Ctx) string {
+	sc := t.span(c).SpanContext()
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
+
+// Observe implements errhandler.Observer
+func (t *Tracing) Observe(c *fiber.Ctx, e *errhandler.Event) {
+	span := t.span(c)
+	if !span.IsRecording() {
+		return
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.ExceptionType(e.Type),
+		semconv.ExceptionMessage(e.Err.Error()),
+		attribute.Bool("error.panic", e.Panic),
+		attribute.String("error.fingerprint", e.Fingerprint),
+	}
+	if frames := e.Frames(); len(frames) > 0 {
+		var b strings.Builder
+		for _, f := range frames {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		}
+		attrs = append(attrs, semconv.ExceptionStacktrace(b.String()))
+	}
+	span.AddEvent(semconv.ExceptionEventName, trace.WithAttributes(attrs...))
+
+	span.SetAttributes(attribute.Int("http.response.status_code", e.StatusCode))
+	if e.Code != "" {
+		span.SetAttributes(attribute.String("error.code", e.Code))
+	}
+	if e.StatusCode >= fiber.StatusInternalServerError {
+		msg := e.Err.Error()
+		var he errhandler.HTTPError
+		if errors.As(e.Err, &he) {
+			msg = he.Message()
+		}
+		span.SetStatus(codes.Error, msg)
+	}
+}
@@ -0,0 +1,82 @@
+package otel
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gofiber/fiber"
+	errhandler "github.com/hendratommy/fiber-errhandler"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracer := provider.Tracer("test")
+	tracing := NewTracing()
+
+	app := fiber.New()
+	// tracing middleware
+	app.Use(func(c *fiber.Ctx) {
+		ctx, span := tracer.Start(context.Background(), c.Path())
+		defer span.End()
+		c.Locals(SpanContextKey, ctx)
+		c.Next()
+	})
+	app.Use(errhandler.New(errhandler.Config{
+		Observers: []errhandler.Observer{tracing},
+		TraceID:   tracing.TraceID,
+	}))
+	app.Get("/panic", func(c *fiber.Ctx) {
+		panic("i'm panic")
+	})
+	app.Get("/400", func(c *fiber.Ctx) {
+		c.Next(errhandler.NewHttpError(fiber.StatusBadRequest, "Bad request", nil).WithCode("BAD_REQUEST"))
+	})
+
+	req := httptest.NewRequest("GET", "/panic", nil)
+	req.Header.Set("Accept", "application/json")
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+		b := make(map[string]interface{})
+		if err := json.NewDecoder(resp.Body).Decode(&b); err != nil {
+			assert.NoError(t, err)
+		} else if spans := exporter.GetSpans(); assert.Len(t, spans, 1) {
+			span := spans[0]
+			assert.Equal(t, span.SpanContext.TraceID().String(), b["traceId"])
+			assert.Equal(t, codes.Error, span.Status.Code)
+			if assert.Len(t, span.Events, 1) {
+				attrs := make(map[string]interface{})
+				for _, kv := range span.Events[0].Attributes {
+					attrs[string(kv.Key)] = kv.Value.AsInterface()
+				}
+				assert.Equal(t, "exception", span.Events[0].Name)
+				assert.Equal(t, "i'm panic", attrs["exception.message"])
+				assert.Equal(t, "string", attrs["exception.type"])
+				assert.Contains(t, attrs["exception.stacktrace"], "TestTracing")
+			}
+		}
+	}
+
+	exporter.Reset()
+	req = httptest.NewRequest("GET", "/400", nil)
+	if _, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else if spans := exporter.GetSpans(); assert.Len(t, spans, 1) {
+		span := spans[0]
+		// only 5xx set the span status
+		assert.Equal(t, codes.Unset, span.Status.Code)
+		attrs := make(map[string]interface{})
+		for _, kv := range span.Attributes {
+			attrs[string(kv.Key)] = kv.Value.AsInterface()
+		}
+		assert.Equal(t, "BAD_REQUEST", attrs["error.code"])
+		assert.Equal(t, int64(fiber.StatusBadRequest), attrs["http.response.status_code"])
+	}
+}
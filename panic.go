@@ -0,0 +1,70 @@
+package fiber_errhandler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber"
+	"net"
+	"net/http"
+)
+
+// PanicPolicy defines what the middleware does with a recovered panic
+type PanicPolicy int
+
+const (
+	// Recover the panic and render it as a 500 error
+	PanicRecover PanicPolicy = iota
+	// Recover the panic, log and observe it, then panic again with the original value so outer supervisors see it
+	PanicRepanic
+	// Recover the panic, log and observe it, then close the connection without a response
+	PanicAbort
+)
+
+// PanicError is the error of a panic with a non-error value, e.g. `panic(42)`.
+// Panics with an error value are handled as that error.
+type PanicError struct {
+	Value interface{}
+}
+
+func (pe *PanicError) Error() string {
+	return fmt.Sprint(pe.Value)
+}
+
+// Convert a recovered value to an error, keeping the original value
+func panicError(r interface{}) error {
+	if err, ok := r.(error); ok {
+		return err
+	}
+	return &PanicError{Value: r}
+}
+
+// Get the value the handler panicked with
+func panicValue(err error) interface{} {
+	if pe, ok := err.(*PanicError); ok {
+		return pe.Value
+	}
+	return err
+}
+
+// Report whether the panic asks to abort the connection, like `http.ErrAbortHandler` does for net/http
+func abortPanic(err error) bool {
+	return errors.Is(err, http.ErrAbortHandler)
+}
+
+// Close the connection without sending a response
+func abortConnection(c *fiber.Ctx) {
+	c.Fasthttp.HijackSetNoResponse(true)
+	c.Fasthttp.Hijack(func(net.Conn) {})
+}
+
+// Type name of the error reported to trackers, the type of the value for panics with a non-error value
+// and the type of the cause for transient errors
+func errorType(err error) string {
+	if pe, ok := err.(*PanicError); ok {
+		return fmt.Sprintf("%T", pe.Value)
+	}
+	if te, ok := err.(*transientError); ok {
+		return errorType(te.cause)
+	}
+	return fmt.Sprintf("%T", err)
+}
@@ -0,0 +1,149 @@
+package fiber_errhandler
+
+import (
+	"bytes"
+	"errors"
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type panicValueStruct struct {
+	ID int
+}
+
+type observerFunc func(*fiber.Ctx, *Event)
+
+func (f observerFunc) Observe(c *fiber.Ctx, e *Event) {
+	f(c, e)
+}
+
+func TestErrHandler_panic_value(t *testing.T) {
+	var got error
+	app := fiber.New()
+	app.Use(New(Config{
+		Handler: func(c *fiber.Ctx, err error, next func(...interface{})) {
+			got = err
+			next(err)
+		},
+	}))
+	app.Get("/int", func(c *fiber.Ctx) {
+		panic(42)
+	})
+	app.Get("/struct", func(c *fiber.Ctx) {
+		panic(panicValueStruct{ID: 1})
+	})
+	sentinel := errors.New("sentinel")
+	app.Get("/error", func(c *fiber.Ctx) {
+		panic(sentinel)
+	})
+
+	for _, tc := range []struct {
+		target  string
+		value   interface{}
+		message string
+	}{
+		{"/int", 42, "42"},
+		{"/struct", panicValueStruct{ID: 1}, "{1}"},
+		{"/error", sentinel, "sentinel"},
+	} {
+		req := httptest.NewRequest("GET", tc.target, nil)
+		if resp, err := app.Test(req); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+			assert.Equal(t, tc.message, got.Error())
+			assert.Equal(t, tc.value, panicValue(got))
+		}
+	}
+
+	var pe *PanicError
+	assert.False(t, errors.As(sentinel, &pe))
+}
+
+func TestErrHandler_panic_repanic(t *testing.T) {
+	var recovered interface{}
+	var observed *Event
+	out := &bytes.Buffer{}
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) {
+		defer func() {
+			recovered = recover()
+			c.Status(fiber.StatusBadGateway).SendString("supervisor")
+		}()
+		c.Next()
+	})
+	app.Use(New(Config{
+		Log:         true,
+		Output:      out,
+		PanicPolicy: PanicRepanic,
+		Observers: []Observer{observerFunc(func(c *fiber.Ctx, e *Event) {
+			observed = e
+		})},
+	}))
+	app.Get("/", func(c *fiber.Ctx) {
+		panic(panicValueStruct{ID: 7})
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusBadGateway, resp.StatusCode)
+		assert.Equal(t, panicValueStruct{ID: 7}, recovered)
+		if assert.NotNil(t, observed) {
+			assert.True(t, observed.Panic)
+			assert.True(t, observed.Aborted)
+			assert.Equal(t, fiber.StatusInternalServerError, observed.StatusCode)
+		}
+		assert.Contains(t, out.String(), "{7} (fingerprint: ")
+	}
+}
+
+func TestErrHandler_panic_abort(t *testing.T) {
+	out := &bytes.Buffer{}
+	app := fiber.New()
+	app.Use(New(Config{
+		Log:    true,
+		Output: out,
+	}))
+	app.Get("/abort", func(c *fiber.Ctx) {
+		panic(http.ErrAbortHandler)
+	})
+	app.Get("/ok", func(c *fiber.Ctx) {
+		c.SendString("OK")
+	})
+	var observed *Event
+	abortApp := fiber.New()
+	abortApp.Use(New(Config{
+		Log:         true,
+		Output:      out,
+		PanicPolicy: PanicAbort,
+		Observers: []Observer{observerFunc(func(c *fiber.Ctx, e *Event) {
+			observed = e
+		})},
+	}))
+	abortApp.Get("/", func(c *fiber.Ctx) {
+		panic("boom")
+	})
+
+	// no response is sent
+	_, err := app.Test(httptest.NewRequest("GET", "/abort", nil))
+	assert.Error(t, err)
+	assert.Empty(t, out.String())
+
+	_, err = abortApp.Test(httptest.NewRequest("GET", "/", nil))
+	assert.Error(t, err)
+	assert.Contains(t, out.String(), "boom (fingerprint: ")
+	if assert.NotNil(t, observed) {
+		assert.True(t, observed.Aborted)
+		assert.Equal(t, fiber.StatusInternalServerError, observed.StatusCode)
+	}
+
+	if resp, err := app.Test(httptest.NewRequest("GET", "/ok", nil)); assert.NoError(t, err) {
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	}
+}
@@ -0,0 +1,141 @@
+package fiber_errhandler
+
+import (
+	"bytes"
+	"github.com/gofiber/fiber"
+	"html/template"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RecentError is an entry of RecentErrors
+type RecentError struct {
+	Time             time.Time `json:"time"`
+	RequestID        string    `json:"requestId,omitempty"`
+	Method           string    `json:"method"`
+	Route            string    `json:"route"`
+	Path             string    `json:"path"`
+	StatusCode       int       `json:"status"`
+	Message          string    `json:"message"`
+	Panic            bool      `json:"panic"`
+	StackFingerprint string    `json:"stackFingerprint,omitempty"`
+	Fingerprint      string    `json:"fingerprint"`
+}
+
+// RecentErrors records the last handled errors in a bounded ring buffer.
+// Add it to `Config.Observers` and mount RecentErrors.Handler on an admin route, e.g.
+//
+//	recent := errhandler.NewRecentErrors(100)
+//	app.Use(errhandler.New(errhandler.Config{Observers: []errhandler.Observer{recent}}))
+//	app.Get("/admin/errors", recent.Handler())
+type RecentErrors struct {
+	mu      sync.Mutex
+	entries []RecentError
+	next    int
+	full    bool
+}
+
+// NewRecentErrors returns a RecentErrors keeping the last `size` errors
+func NewRecentErrors(size int) *RecentErrors {
+	if size <= 0 {
+		size = 100
+	}
+	return &RecentErrors{
+		entries: make([]RecentError, size),
+	}
+}
+
+// Observe implements Observer
+func (r *RecentErrors) Observe(c *fiber.Ctx, e *Event) {
+	entry := RecentError{
+		Time:             e.Time,
+		RequestID:        e.RequestID,
+		Method:           e.Method,
+		Route:            e.Route,
+		Path:             e.Path,
+		StatusCode:       e.StatusCode,
+		Message:          errorMessage(e.Err),
+		Panic:            e.Panic,
+		StackFingerprint: stackFingerprint(e.Stack),
+		Fingerprint:      e.Fingerprint,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Entries returns the recorded errors, newest first
+func (r *RecentErrors) Entries() []RecentError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := r.next
+	if r.full {
+		n = len(r.entries)
+	}
+	entries := make([]RecentError, 0, n)
+	for i := 1; i <= n; i++ {
+		entries = append(entries, r.entries[(r.next-i+len(r.entries))%len(r.entries)])
+	}
+	return entries
+}
+
+// Match the status filter, either an exact status code (`404`) or a class (`5xx`)
+func matchStatus(filter string, status int) bool {
+	if filter == "" {
+		return true
+	}
+	filter = strings.ToLower(filter)
+	if len(filter) == 3 && strings.HasSuffix(filter, "xx") {
+		return strconv.Itoa(status/100) == filter[:1]
+	}
+	return strconv.Itoa(status) == filter
+}
+
+var recentErrorsTemplate = template.Must(template.New("recent").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Recent errors</title></head>
+<body>
+<table>
+<tr><th>Time</th><th>Request ID</th><th>Method</th><th>Route</th><th>Path</th><th>Status</th><th>Message</th><th>Panic</th><th>Stack fingerprint</th><th>Fingerprint</th></tr>
+{{range .}}<tr><td>{{.Time.Format "2006-01-02T15:04:05.000Z07:00"}}</td><td>{{.RequestID}}</td><td>{{.Method}}</td><td>{{.Route}}</td><td>{{.Path}}</td><td>{{.StatusCode}}</td><td>{{.Message}}</td><td>{{.Panic}}</td><td>{{.StackFingerprint}}</td><td>{{.Fingerprint}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// Handler returns a handler listing the recorded errors as JSON, or HTML if the client accepts it.
+// Errors can be filtered with the `status` (e.g. `404` or `5xx`) and `route` query parameters.
+func (r *RecentErrors) Handler() func(*fiber.Ctx) {
+	return func(c *fiber.Ctx) {
+		status := c.Query("status")
+		route := c.Query("route")
+
+		entries := []RecentError{}
+		for _, e := range r.Entries() {
+			if matchStatus(status, e.StatusCode) && (route == "" || route == e.Route) {
+				entries = append(entries, e)
+			}
+		}
+
+		if contentTypeFromAccept(c, fiber.MIMEApplicationJSON) == fiber.MIMETextHTML {
+			buf := &bytes.Buffer{}
+			if err := recentErrorsTemplate.Execute(buf, entries); err != nil {
+				c.Next(err)
+				return
+			}
+			c.Set(fiber.HeaderContentType, fiber.MIMETextHTML)
+			c.SendBytes(buf.Bytes())
+			return
+		}
+		c.JSON(entries)
+	}
+}
@@ -0,0 +1,84 @@
+package fiber_errhandler
+
+import (
+	"encoding/json"
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecentErrors(t *testing.T) {
+	recent := NewRecentErrors(2)
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Observers: []Observer{recent},
+	}))
+	app.Get("/admin/errors", recent.Handler())
+	app.Get("/users/:id", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusNotFound, "User not found", nil))
+	})
+	app.Get("/panic", func(c *fiber.Ctx) {
+		panic("i'm panic")
+	})
+
+	for _, path := range []string{"/panic", "/users/1", "/users/2"} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set(fiber.HeaderXRequestID, "req-"+path)
+		if _, err := app.Test(req); err != nil {
+			assert.NoError(t, err)
+		}
+	}
+
+	// the buffer only keeps the last 2 errors
+	entries := recent.Entries()
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "/users/2", entries[0].Path)
+		assert.Equal(t, "req-/users/2", entries[0].RequestID)
+		assert.Equal(t, "/users/:id", entries[0].Route)
+		assert.Equal(t, fiber.StatusNotFound, entries[0].StatusCode)
+		assert.Equal(t, "User not found", entries[0].Message)
+		assert.Equal(t, "/users/1", entries[1].Path)
+	}
+
+	app.Get("/panic2", func(c *fiber.Ctx) {
+		panic("i'm panic")
+	})
+	if _, err := app.Test(httptest.NewRequest("GET", "/panic2", nil)); err != nil {
+		assert.NoError(t, err)
+	}
+
+	req := httptest.NewRequest("GET", "/admin/errors?status=5xx", nil)
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var b []RecentError
+		if err := json.NewDecoder(resp.Body).Decode(&b); err != nil {
+			assert.NoError(t, err)
+		} else if assert.Len(t, b, 1) {
+			assert.Equal(t, "/panic2", b[0].Route)
+			assert.True(t, b[0].Panic)
+			assert.Equal(t, "i'm panic", b[0].Message)
+			assert.NotEmpty(t, b[0].StackFingerprint)
+		}
+	}
+
+	req = httptest.NewRequest("GET", "/admin/errors?route=/users/:id", nil)
+	req.Header.Set("Accept", "text/html")
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, fiber.MIMETextHTML, resp.Header.Get(fiber.HeaderContentType))
+
+		if b, err := ioutil.ReadAll(resp.Body); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Contains(t, string(b), "<td>/users/:id</td><td>/users/2</td><td>404</td><td>User not found</td>")
+			assert.NotContains(t, string(b), "/panic2")
+		}
+	}
+}
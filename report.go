@@ -0,0 +1,171 @@
+package fiber_errhandler
+
+import (
+	"github.com/gofiber/fiber"
+	"sync"
+	"sync/atomic"
+)
+
+// Reporter sends reportable errors to an error tracking service.
+// Report is called asynchronously from the background worker of Reporting, one report at a time.
+type Reporter interface {
+	Report(r *Report)
+}
+
+// ReportRequest describes the request which failed
+type ReportRequest struct {
+	Method      string
+	URL         string
+	QueryString string
+	Headers     map[string]string
+}
+
+// ReportUser describes the user who made the request
+type ReportUser struct {
+	ID        string
+	Email     string
+	Username  string
+	IPAddress string
+}
+
+// Report is the context of a reportable error
+type Report struct {
+	Event
+	Request ReportRequest
+	User    ReportUser
+	Tags    map[string]string
+}
+
+// ReportingConfig ...
+type ReportingConfig struct {
+	// Filter decides which errors are reported
+	// Optional. Default: 5xx errors and panics
+	Filter func(*Event) bool
+	// User returns the user who made the request
+	// Optional. Default: user with the request IP address
+	User func(*fiber.Ctx) ReportUser
+	// Tags returns additional tags of the report
+	// Optional. Default: nil
+	Tags func(*fiber.Ctx) map[string]string
+	// QueueSize is the number of reports waiting to be sent, reports are dropped when the queue is full
+	// Optional. Default: 100
+	QueueSize int
+}
+
+// Request headers never sent with reports
+var reportRedactedHeaders = map[string]bool{
+	fiber.HeaderAuthorization:      true,
+	fiber.HeaderProxyAuthorization: true,
+	fiber.HeaderCookie:             true,
+}
+
+// Reporting is an Observer which passes reportable errors to a Reporter.
+// Reports are queued and sent by a background worker so reporting never blocks requests.
+type Reporting struct {
+	reporter Reporter
+	cfg      ReportingConfig
+	queue    chan *Report
+	dropped  uint64
+	done     chan struct{}
+
+	// guards sending to queue against Close
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewReporting starts the background worker passing reportable errors to reporter
+func NewReporting(reporter Reporter, config ...ReportingConfig) *Reporting {
+	var cfg ReportingConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Filter == nil {
+		cfg.Filter = func(e *Event) bool {
+			return e.Panic || e.StatusCode >= fiber.StatusInternalServerError
+		}
+	}
+	if cfg.User == nil {
+		cfg.User = func(c *fiber.Ctx) ReportUser {
+			return ReportUser{IPAddress: c.IP()}
+		}
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+
+	r := &Reporting{
+		reporter: reporter,
+		cfg:      cfg,
+		queue:    make(chan *Report, cfg.QueueSize),
+		done:     make(chan struct{}),
+	}
+	go r.work()
+	return r
+}
+
+func (r *Reporting) work() {
+	defer close(r.done)
+	for report := range r.queue {
+		r.reporter.Report(report)
+	}
+}
+
+// Observe implements Observer
+func (r *Reporting) Observe(c *fiber.Ctx, e *Event) {
+	if !r.cfg.Filter(e) {
+		return
+	}
+
+	report := &Report{
+		Event: *e,
+		Request: ReportRequest{
+			Method:      e.Method,
+			URL:         copyString(c.BaseURL() + c.Path()),
+			QueryString: string(c.Fasthttp.QueryArgs().QueryString()),
+			Headers:     make(map[string]string),
+		},
+		User: r.cfg.User(c),
+	}
+	c.Fasthttp.Request.Header.VisitAll(func(key, value []byte) {
+		if k := string(key); !reportRedactedHeaders[k] {
+			report.Request.Headers[k] = string(value)
+		}
+	})
+	if r.cfg.Tags != nil {
+		report.Tags = r.cfg.Tags(c)
+	}
+
+	r.enqueue(report)
+}
+
+// Queue the report, dropping it if the queue is full or Reporting is closed
+func (r *Reporting) enqueue(report *Report) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		atomic.AddUint64(&r.dropped, 1)
+		return
+	}
+	select {
+	case r.queue <- report:
+	default:
+		atomic.AddUint64(&r.dropped, 1)
+	}
+}
+
+// Dropped returns the number of reports dropped because the queue was full or Reporting was closed
+func (r *Reporting) Dropped() uint64 {
+	return atomic.LoadUint64(&r.dropped)
+}
+
+// Close stops accepting reports and waits for the queued ones to be passed to the reporter.
+// Reports observed after Close are dropped.
+func (r *Reporting) Close() {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+	<-r.done
+}
@@ -0,0 +1,55 @@
+package fiber_errhandler
+
+import (
+	"github.com/gofiber/fiber"
+	"strings"
+)
+
+// DefaultKeepHeaders are the response headers kept when `Config.Reset` is enabled and `Config.KeepHeaders` is not set
+var DefaultKeepHeaders = []string{
+	// CORS
+	fiber.HeaderAccessControlAllowOrigin,
+	fiber.HeaderAccessControlAllowCredentials,
+	fiber.HeaderAccessControlAllowHeaders,
+	fiber.HeaderAccessControlAllowMethods,
+	fiber.HeaderAccessControlExposeHeaders,
+	fiber.HeaderAccessControlMaxAge,
+	fiber.HeaderVary,
+	// Security
+	fiber.HeaderStrictTransportSecurity,
+	fiber.HeaderContentSecurityPolicy,
+	fiber.HeaderXContentTypeOptions,
+	fiber.HeaderXFrameOptions,
+	fiber.HeaderXXSSProtection,
+	fiber.HeaderReferrerPolicy,
+	// Tracing
+	fiber.HeaderXRequestID,
+}
+
+// Clear the body and strip the response headers which are not allow-listed,
+// so nothing set by the failed handler leaks into the error response.
+func resetResponse(c *fiber.Ctx, cfg Config) {
+	keep := cfg.KeepHeaders
+	if keep == nil {
+		keep = DefaultKeepHeaders
+	}
+
+	var del []string
+	c.Fasthttp.Response.Header.VisitAll(func(key, value []byte) {
+		k := string(key)
+		// managed by fasthttp
+		if k == fiber.HeaderContentLength || k == fiber.HeaderConnection {
+			return
+		}
+		for _, h := range keep {
+			if strings.EqualFold(h, k) {
+				return
+			}
+		}
+		del = append(del, k)
+	})
+	for _, k := range del {
+		c.Fasthttp.Response.Header.Del(k)
+	}
+	c.Fasthttp.Response.ResetBody()
+}
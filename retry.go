@@ -0,0 +1,144 @@
+package fiber_errhandler
+
+import (
+	"context"
+	"errors"
+	"github.com/gofiber/fiber"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Report whether the error is marked retryable
+func retryable(err error) bool {
+	var r Retryable
+	return errors.As(err, &r) && r.Retryable()
+}
+
+// Suggested retry delay of a retryable error, zero if none
+func retryAfter(err error) time.Duration {
+	var r Retryable
+	if errors.As(err, &r) && r.Retryable() {
+		return r.RetryAfter()
+	}
+	return 0
+}
+
+// Retry delay in whole seconds, rounded up as `Retry-After` does not allow fractions
+func retryAfterSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// Set the `Retry-After` header for retryable errors with a suggested delay
+func setRetryAfter(c *fiber.Ctx, err error) {
+	if after := retryAfter(err); after > 0 {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfterSeconds(after)))
+	}
+}
+
+// Add the retry hints to the error body
+func addRetryFields(body fiber.Map, err error) {
+	var r Retryable
+	if !errors.As(err, &r) || !r.Retryable() {
+		return
+	}
+	body["retryable"] = true
+	if after := r.RetryAfter(); after > 0 {
+		body["retryAfter"] = retryAfterSeconds(after)
+	}
+	if note := r.IdempotencyNote(); note != "" {
+		body["idempotency"] = note
+	}
+}
+
+// IsTransient reports whether the error is a well-known transient failure:
+// timeouts, refused, reset or aborted connections, or an error already marked retryable.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if retryable(err) || isTimeout(err) {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+// A transient error marked retryable, keeping the original error in the chain
+type transientError struct {
+	HTTPError
+	cause error
+	after time.Duration
+	note  string
+}
+
+func (te *transientError) Unwrap() error {
+	return te.cause
+}
+
+// Error returns the cause, so distinct transient failures are logged and grouped apart
+func (te *transientError) Error() string {
+	return te.cause.Error()
+}
+
+// Copy of the error with another message, the cause is kept
+func (te *transientError) withMessage(message string) HTTPError {
+	cp := *te
+	if mc, ok := te.HTTPError.(messageCopier); ok {
+		cp.HTTPError = mc.withMessage(message)
+	} else {
+		cp.HTTPError = &localizedError{HTTPError: te.HTTPError, message: message}
+	}
+	return &cp
+}
+
+func (te *transientError) Retryable() bool {
+	return true
+}
+
+func (te *transientError) RetryAfter() time.Duration {
+	return te.after
+}
+
+func (te *transientError) IdempotencyNote() string {
+	return te.note
+}
+
+// RetryTransient returns a transformer which marks transient errors (see IsTransient) as retryable after `after`.
+// Errors which are not HTTPError become a 504 for timeouts and a 503 otherwise.
+func RetryTransient(after time.Duration) Transformer {
+	return func(c *fiber.Ctx, err error) error {
+		if retryable(err) || !IsTransient(err) {
+			return err
+		}
+		te := &transientError{cause: err, after: after}
+		var r Retryable
+		if errors.As(err, &r) {
+			te.note = r.IdempotencyNote()
+		}
+		var he HTTPError
+		if errors.As(err, &he) {
+			te.HTTPError = he
+		} else if isTimeout(err) {
+			te.HTTPError = NewHttpError(fiber.StatusGatewayTimeout, http.StatusText(fiber.StatusGatewayTimeout), nil)
+		} else {
+			te.HTTPError = NewHttpError(fiber.StatusServiceUnavailable, http.StatusText(fiber.StatusServiceUnavailable), nil)
+		}
+		return te
+	}
+}
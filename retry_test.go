@@ -0,0 +1,139 @@
+package fiber_errhandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsTransient(t *testing.T) {
+	assert.True(t, IsTransient(context.DeadlineExceeded))
+	assert.True(t, IsTransient(fmt.Errorf("query: %w", syscall.ECONNRESET)))
+	assert.True(t, IsTransient(syscall.ECONNREFUSED))
+	assert.True(t, IsTransient(io.ErrUnexpectedEOF))
+	assert.True(t, IsTransient(NewHttpError(fiber.StatusConflict, "Conflict", nil).WithRetry(0)))
+	assert.False(t, IsTransient(errors.New("invalid input")))
+	assert.False(t, IsTransient(NewHttpError(fiber.StatusServiceUnavailable, "Unavailable", nil)))
+	assert.False(t, IsTransient(nil))
+}
+
+func TestErrHandler_retry(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(Config{
+		ContentType:  fiber.MIMEApplicationJSON,
+		Transformers: []Transformer{RetryTransient(2 * time.Second)},
+	}))
+	app.Get("/busy", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusServiceUnavailable, "Busy", nil).
+			WithRetry(1500 * time.Millisecond).
+			WithIdempotencyNote("retry with the same Idempotency-Key"))
+	})
+	app.Get("/timeout", func(c *fiber.Ctx) {
+		c.Next(fmt.Errorf("upstream: %w", context.DeadlineExceeded))
+	})
+	app.Get("/reset", func(c *fiber.Ctx) {
+		c.Next(syscall.ECONNRESET)
+	})
+	app.Get("/invalid", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusBadRequest, "Invalid", nil))
+	})
+
+	for _, tc := range []struct {
+		target     string
+		status     int
+		retryAfter string
+		expected   string
+	}{
+		{"/busy", fiber.StatusServiceUnavailable, "2",
+			`{"message":"Busy","retryable":true,"retryAfter":2,"idempotency":"retry with the same Idempotency-Key"}`},
+		{"/timeout", fiber.StatusGatewayTimeout, "2",
+			`{"message":"Gateway Timeout","retryable":true,"retryAfter":2}`},
+		{"/reset", fiber.StatusServiceUnavailable, "2",
+			`{"message":"Service Unavailable","retryable":true,"retryAfter":2}`},
+		{"/invalid", fiber.StatusBadRequest, "",
+			`{"message":"Invalid"}`},
+	} {
+		req := httptest.NewRequest("GET", tc.target, nil)
+		if resp, err := app.Test(req); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, tc.status, resp.StatusCode)
+			assert.Equal(t, tc.retryAfter, resp.Header.Get("Retry-After"))
+			b := make(map[string]interface{})
+			expected := make(map[string]interface{})
+			assert.NoError(t, json.Unmarshal([]byte(tc.expected), &expected))
+			if err := json.NewDecoder(resp.Body).Decode(&b); err != nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, expected, b)
+			}
+		}
+	}
+}
+
+func TestToStatus_retry(t *testing.T) {
+	s := ToStatus(NewStatusError(GRPCUnavailable, "Unavailable").WithRetry(3 * time.Second))
+	assert.Equal(t, GRPCUnavailable, s.Code)
+	assert.Equal(t, []interface{}{RetryInfo{RetryDelay: 3 * time.Second}}, s.Details)
+}
+
+func TestErrHandler_retry_transient_causes(t *testing.T) {
+	out := &bytes.Buffer{}
+	app := fiber.New()
+	app.Use(New(Config{
+		Log:          true,
+		Output:       out,
+		Transformers: []Transformer{RetryTransient(time.Second)},
+	}))
+	app.Get("/db", func(c *fiber.Ctx) {
+		c.Next(fmt.Errorf("dial db: %w", syscall.ECONNREFUSED))
+	})
+	app.Get("/cache", func(c *fiber.Ctx) {
+		c.Next(fmt.Errorf("call cache: %w", syscall.ECONNRESET))
+	})
+	app.Get("/wrapped", func(c *fiber.Ctx) {
+		c.Next(fmt.Errorf("upstream: %w", &transientCauseError{
+			HTTPError: NewHttpError(fiber.StatusBadGateway, "Bad Gateway", nil),
+			cause:     syscall.ECONNRESET,
+		}))
+	})
+
+	for _, target := range []string{"/db", "/cache"} {
+		if resp, err := app.Test(httptest.NewRequest("GET", target, nil)); assert.NoError(t, err) {
+			assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.True(t, strings.HasPrefix(lines[0], "dial db: connection refused (fingerprint: "))
+		assert.True(t, strings.HasPrefix(lines[1], "call cache: connection reset by peer (fingerprint: "))
+		fp := regexp.MustCompile(`fingerprint: (\w+)`)
+		assert.NotEqual(t, fp.FindStringSubmatch(lines[0])[1], fp.FindStringSubmatch(lines[1])[1])
+	}
+
+	if resp, err := app.Test(httptest.NewRequest("GET", "/wrapped", nil)); assert.NoError(t, err) {
+		assert.Equal(t, fiber.StatusBadGateway, resp.StatusCode)
+		assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+	}
+}
+
+// An HTTPError caused by a transient failure
+type transientCauseError struct {
+	HTTPError
+	cause error
+}
+
+func (e *transientCauseError) Unwrap() error {
+	return e.cause
+}
@@ -0,0 +1,47 @@
+package fiber_errhandler
+
+import (
+	"github.com/gofiber/fiber"
+	"os"
+)
+
+// Locals key holding the config overrides of the current route
+const localsOverrides = "fiber-errhandler.overrides"
+
+// Override returns a handler which overrides the middleware config for the group or route it is mounted on, e.g.
+//
+//	api := app.Group("/api", errhandler.Override(func(cfg *errhandler.Config) {
+//		cfg.ContentType = fiber.MIMEApplicationJSON
+//	}))
+//
+// Overrides are stored in c.Locals and applied in the order they are mounted, on a copy of the config given to New.
+// Filter and Maintenance are evaluated before any route runs, hence cannot be overridden.
+func Override(overrides ...func(*Config)) func(*fiber.Ctx) {
+	return func(c *fiber.Ctx) {
+		prev, _ := c.Locals(localsOverrides).([]func(*Config))
+		all := make([]func(*Config), 0, len(prev)+len(overrides))
+		all = append(append(all, prev...), overrides...)
+		c.Locals(localsOverrides, all)
+		c.Next()
+	}
+}
+
+// Apply the overrides of the current route to cfg
+func routeConfig(c *fiber.Ctx, cfg Config) Config {
+	overrides, _ := c.Locals(localsOverrides).([]func(*Config))
+	if len(overrides) == 0 {
+		return cfg
+	}
+	// make sure appending to slices does not modify the shared config
+	cfg.Transformers = cfg.Transformers[:len(cfg.Transformers):len(cfg.Transformers)]
+	cfg.Observers = cfg.Observers[:len(cfg.Observers):len(cfg.Observers)]
+	filter, maintenance := cfg.Filter, cfg.Maintenance
+	for _, override := range overrides {
+		override(&cfg)
+	}
+	cfg.Filter, cfg.Maintenance = filter, maintenance
+	if cfg.Output == nil {
+		cfg.Output = os.Stderr
+	}
+	return cfg
+}
@@ -0,0 +1,322 @@
+package fiber_errhandler
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SentryConfig ...
+type SentryConfig struct {
+	// DSN of the Sentry project, e.g. `https://<key>@sentry.example.com/<project>`
+	// Required.
+	DSN string
+	// Environment sent with the events
+	// Optional. Default: ""
+	Environment string
+	// Release sent with the events
+	// Optional. Default: ""
+	Release string
+	// BatchSize is the number of events buffered before they are sent
+	// Optional. Default: 10
+	BatchSize int
+	// FlushInterval is the maximum time an event stays buffered
+	// Optional. Default: 5s
+	FlushInterval time.Duration
+	// Client used to post the envelopes
+	// Optional. Default: http.Client with a 10s timeout
+	Client *http.Client
+}
+
+// SentryReporter is a Reporter posting events in Sentry envelope format.
+// Sentry accepts one event per envelope, a batch is sent as consecutive requests.
+type SentryReporter struct {
+	cfg      SentryConfig
+	endpoint string
+	auth     string
+
+	mu      sync.Mutex
+	batch   [][]byte
+	failed  uint64
+	stop    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// NewSentryReporter parses the DSN and starts the periodic flush
+func NewSentryReporter(config SentryConfig) (*SentryReporter, error) {
+	cfg := config
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 10
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 5 * time.Second
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	dsn, err := url.Parse(cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sentry DSN: %w", err)
+	}
+	if dsn.User == nil || dsn.User.Username() == "" {
+		return nil, fmt.Errorf("invalid sentry DSN: missing public key")
+	}
+	i := strings.LastIndexByte(dsn.Path, '/')
+	if i == -1 || i == len(dsn.Path)-1 {
+		return nil, fmt.Errorf("invalid sentry DSN: missing project id")
+	}
+	project := dsn.Path[i+1:]
+
+	s := &SentryReporter{
+		cfg:      cfg,
+		endpoint: fmt.Sprintf("%s://%s%s/api/%s/envelope/", dsn.Scheme, dsn.Host, dsn.Path[:i], project),
+		auth:     "Sentry sentry_version=7, sentry_client=fiber-errhandler/1.0, sentry_key=" + dsn.User.Username(),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go s.loop()
+	return s, nil
+}
+
+func (s *SentryReporter) loop() {
+	defer close(s.stopped)
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.Flush()
+		case <-s.stop:
+			s.Flush()
+			return
+		}
+	}
+}
+
+// Report implements Reporter
+func (s *SentryReporter) Report(r *Report) {
+	envelope, err := s.envelope(r)
+	if err != nil {
+		s.mu.Lock()
+		s.failed++
+		s.mu.Unlock()
+		return
+	}
+
+	s.mu.Lock()
+	s.batch = append(s.batch, envelope)
+	full := len(s.batch) >= s.cfg.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		s.Flush()
+	}
+}
+
+// Flush sends the buffered events
+func (s *SentryReporter) Flush() {
+	s.mu.Lock()
+	batch := s.batch
+	s.batch = nil
+	s.mu.Unlock()
+
+	for _, envelope := range batch {
+		if err := s.send(envelope); err != nil {
+			s.mu.Lock()
+			s.failed++
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Failed returns the number of events which could not be encoded or sent
+func (s *SentryReporter) Failed() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failed
+}
+
+// Close stops the periodic flush and sends the buffered events
+func (s *SentryReporter) Close() {
+	s.once.Do(func() {
+		close(s.stop)
+	})
+	<-s.stopped
+}
+
+func (s *SentryReporter) send(envelope []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(envelope))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sentry responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+type sentryFrame struct {
+	Function string `json:"function,omitempty"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	AbsPath  string `json:"abs_path,omitempty"`
+	Lineno   int    `json:"lineno,omitempty"`
+	InApp    bool   `json:"in_app"`
+}
+
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryException struct {
+	Type       string            `json:"type"`
+	Value      string            `json:"value"`
+	Stacktrace *sentryStacktrace `json:"stacktrace,omitempty"`
+}
+
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Logger      string            `json:"logger"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Transaction string            `json:"transaction,omitempty"`
+	Fingerprint []string          `json:"fingerprint,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Exception   struct {
+		Values []sentryException `json:"values"`
+	} `json:"exception"`
+	Request struct {
+		Method      string            `json:"method"`
+		URL         string            `json:"url"`
+		QueryString string            `json:"query_string,omitempty"`
+		Headers     map[string]string `json:"headers,omitempty"`
+	} `json:"request"`
+	User struct {
+		ID        string `json:"id,omitempty"`
+		Email     string `json:"email,omitempty"`
+		Username  string `json:"username,omitempty"`
+		IPAddress string `json:"ip_address,omitempty"`
+	} `json:"user"`
+}
+
+// Encode the report as a Sentry envelope holding a single event
+func (s *SentryReporter) envelope(r *Report) ([]byte, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	ev := sentryEvent{
+		EventID:     hex.EncodeToString(id),
+		Timestamp:   r.Time.UTC().Format(time.RFC3339Nano),
+		Platform:    "go",
+		Level:       "error",
+		Logger:      "fiber-errhandler",
+		Environment: s.cfg.Environment,
+		Release:     s.cfg.Release,
+		Transaction: r.Method + " " + r.Route,
+		Fingerprint: []string{r.Fingerprint},
+		Tags: map[string]string{
+			"status": strconv.Itoa(r.StatusCode),
+			"route":  r.Route,
+			"panic":  strconv.FormatBool(r.Panic),
+		},
+	}
+	if r.Panic {
+		ev.Level = "fatal"
+	}
+	ev.ServerName, _ = os.Hostname()
+	if r.Code != "" {
+		ev.Tags["code"] = r.Code
+	}
+	for k, v := range r.Tags {
+		ev.Tags[k] = v
+	}
+	if r.RequestID != "" {
+		ev.Tags["request_id"] = r.RequestID
+	}
+
+	exception := sentryException{
+		Type:  errorType(r.Err),
+		Value: r.Err.Error(),
+	}
+	if frames := stackFrames(r.Stack); len(frames) > 0 {
+		exception.Stacktrace = &sentryStacktrace{}
+		// Sentry expects the oldest frame first
+		for i := len(frames) - 1; i >= 0; i-- {
+			f := frames[i]
+			module, function := splitFunction(f.Function)
+			exception.Stacktrace.Frames = append(exception.Stacktrace.Frames, sentryFrame{
+				Function: function,
+				Module:   module,
+				Filename: f.File[strings.LastIndexByte(f.File, '/')+1:],
+				AbsPath:  f.File,
+				Lineno:   f.Line,
+				InApp:    !strings.HasPrefix(module, "github.com/gofiber/") && !strings.HasPrefix(module, "github.com/valyala/"),
+			})
+		}
+	}
+	ev.Exception.Values = []sentryException{exception}
+
+	ev.Request.Method = r.Request.Method
+	ev.Request.URL = r.Request.URL
+	ev.Request.QueryString = r.Request.QueryString
+	ev.Request.Headers = r.Request.Headers
+	ev.User.ID = r.User.ID
+	ev.User.Email = r.User.Email
+	ev.User.Username = r.User.Username
+	ev.User.IPAddress = r.User.IPAddress
+
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+	header, err := json.Marshal(map[string]string{
+		"event_id": ev.EventID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339Nano),
+		"dsn":      s.cfg.DSN,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	buf.Write(header)
+	fmt.Fprintf(buf, "\n{\"type\":\"event\",\"length\":%d}\n", len(payload))
+	buf.Write(payload)
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// Split a fully qualified function name into its package path and function name
+func splitFunction(name string) (module string, function string) {
+	slash := strings.LastIndexByte(name, '/')
+	dot := strings.IndexByte(name[slash+1:], '.')
+	if dot == -1 {
+		return "", name
+	}
+	return name[:slash+1+dot], name[slash+1+dot+1:]
+}
@@ -0,0 +1,150 @@
+package fiber_errhandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSentryReporter(t *testing.T) {
+	var mu sync.Mutex
+	var envelopes [][]byte
+	var auth, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		envelopes = append(envelopes, b)
+		auth = r.Header.Get("X-Sentry-Auth")
+		path = r.URL.Path
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	sentry, err := NewSentryReporter(SentryConfig{
+		DSN:         "http://public@" + server.Listener.Addr().String() + "/42",
+		Environment: "test",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	reporting := NewReporting(sentry, ReportingConfig{
+		User: func(c *fiber.Ctx) ReportUser {
+			return ReportUser{ID: c.Get("X-User")}
+		},
+		Tags: func(c *fiber.Ctx) map[string]string {
+			return map[string]string{"service": "users"}
+		},
+	})
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Observers: []Observer{reporting},
+	}))
+	app.Get("/400", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusBadRequest, "Bad request", nil))
+	})
+	app.Get("/panic", func(c *fiber.Ctx) {
+		panic("i'm panic")
+	})
+
+	for _, p := range []string{"/400", "/panic"} {
+		req := httptest.NewRequest("GET", p, nil)
+		req.Header.Set("X-User", "u1")
+		req.Header.Set("Authorization", "Bearer secret")
+		if _, err := app.Test(req); err != nil {
+			assert.NoError(t, err)
+		}
+	}
+	reporting.Close()
+	sentry.Close()
+
+	assert.Equal(t, uint64(0), reporting.Dropped())
+	assert.Equal(t, uint64(0), sentry.Failed())
+
+	mu.Lock()
+	defer mu.Unlock()
+	// only the panic is reportable
+	if !assert.Len(t, envelopes, 1) {
+		return
+	}
+	assert.Equal(t, "/api/42/envelope/", path)
+	assert.Contains(t, auth, "sentry_key=public")
+
+	lines := bytes.Split(bytes.TrimSpace(envelopes[0]), []byte("\n"))
+	if !assert.Len(t, lines, 3) {
+		return
+	}
+	var item map[string]interface{}
+	assert.NoError(t, json.Unmarshal(lines[1], &item))
+	assert.Equal(t, "event", item["type"])
+	assert.Equal(t, float64(len(lines[2])), item["length"])
+
+	var ev map[string]interface{}
+	assert.NoError(t, json.Unmarshal(lines[2], &ev))
+	assert.Equal(t, "fatal", ev["level"])
+	assert.Equal(t, "test", ev["environment"])
+	assert.Equal(t, "users", ev["tags"].(map[string]interface{})["service"])
+	assert.Equal(t, "500", ev["tags"].(map[string]interface{})["status"])
+	assert.Equal(t, "u1", ev["user"].(map[string]interface{})["id"])
+	request := ev["request"].(map[string]interface{})
+	assert.Equal(t, "GET", request["method"])
+	assert.NotContains(t, request["headers"], "Authorization")
+	exception := ev["exception"].(map[string]interface{})["values"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "i'm panic", exception["value"])
+	assert.NotEmpty(t, exception["stacktrace"].(map[string]interface{})["frames"])
+}
+
+type blockingReporter struct {
+	started  chan struct{}
+	release  chan struct{}
+	reported int32
+}
+
+func (r *blockingReporter) Report(report *Report) {
+	if atomic.AddInt32(&r.reported, 1) == 1 {
+		close(r.started)
+	}
+	<-r.release
+}
+
+func TestReporting_queue(t *testing.T) {
+	reporter := &blockingReporter{started: make(chan struct{}), release: make(chan struct{})}
+	reporting := NewReporting(reporter, ReportingConfig{QueueSize: 2})
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Observers: []Observer{reporting},
+	}))
+	app.Get("/panic", func(c *fiber.Ctx) {
+		panic("i'm panic")
+	})
+	request := func() {
+		if resp, err := app.Test(httptest.NewRequest("GET", "/panic", nil)); assert.NoError(t, err) {
+			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+		}
+	}
+
+	// the worker blocks on the first report
+	request()
+	<-reporter.started
+	// two reports fill the queue, the others are dropped without blocking requests
+	for i := 0; i < 5; i++ {
+		request()
+	}
+	assert.Equal(t, uint64(3), reporting.Dropped())
+
+	close(reporter.release)
+	reporting.Close()
+	assert.Equal(t, int32(3), atomic.LoadInt32(&reporter.reported))
+
+	// reports after Close are dropped
+	request()
+	assert.Equal(t, uint64(4), reporting.Dropped())
+}
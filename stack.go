@@ -0,0 +1,54 @@
+package fiber_errhandler
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Number of stack frames used to build the stack fingerprint
+const fingerprintFrames = 5
+
+// Capture the stack of the caller's caller, used to record where a panic happened
+func callers() []uintptr {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	return pcs[:n]
+}
+
+// Get the frames of the stack, runtime frames are skipped
+func stackFrames(stack []uintptr) []runtime.Frame {
+	var frames []runtime.Frame
+	if len(stack) == 0 {
+		return frames
+	}
+	it := runtime.CallersFrames(stack)
+	for {
+		frame, more := it.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			frames = append(frames, frame)
+		}
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// Hash the top frames of the stack, return empty string if there is no stack
+func stackFingerprint(stack []uintptr) string {
+	frames := stackFrames(stack)
+	if len(frames) == 0 {
+		return ""
+	}
+	if len(frames) > fingerprintFrames {
+		frames = frames[:fingerprintFrames]
+	}
+	h := sha1.New()
+	for _, f := range frames {
+		fmt.Fprintf(h, "%s:%d\n", f.Function, f.Line)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,102 @@
+package fiber_errhandler
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/gofiber/fiber"
+	"net"
+	"strings"
+	"unicode/utf8"
+)
+
+// MIMETextEventStream is the content type of Server-Sent Events
+const MIMETextEventStream = "text/event-stream"
+
+// WebSocket close codes used for errors
+const (
+	WebSocketClosePolicyViolation = 1008
+	WebSocketCloseInternalError   = 1011
+	WebSocketCloseTryAgainLater   = 1013
+)
+
+// Magic value of the handshake, see RFC 6455
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Maximum length of a close frame reason, the control frame payload is limited to 125 bytes
+const maxCloseReason = 123
+
+// Report whether the request is a WebSocket handshake
+func webSocketUpgrade(c *fiber.Ctx) bool {
+	return strings.EqualFold(c.Get(fiber.HeaderUpgrade), "websocket") && c.Get(fiber.HeaderSecWebSocketKey) != ""
+}
+
+// Report whether the handler responds, or the client expects, a Server-Sent Events stream
+func eventStream(c *fiber.Ctx) bool {
+	if strings.HasPrefix(string(c.Fasthttp.Response.Header.ContentType()), MIMETextEventStream) {
+		return true
+	}
+	return strings.Contains(strings.ToLower(c.Get(fiber.HeaderAccept)), MIMETextEventStream)
+}
+
+// Report whether the handler already wrote events to a buffered stream, the error is then appended as an event
+func eventStreamStarted(c *fiber.Ctx) bool {
+	return !responseStreamed(c) && len(c.Fasthttp.Response.Body()) > 0 && eventStream(c)
+}
+
+// Send error as an `event: error` frame with the JSON error as data.
+// The status code is kept if the handler already wrote events.
+func handleSSE(c *fiber.Ctx, cfg Config, args ...interface{}) {
+	httpErr := getHTTPError(args...)
+	data, err := json.Marshal(errorBody(c, cfg, httpErr))
+	if err != nil {
+		data, _ = json.Marshal(fiber.Map{"message": httpErr.Message()})
+	}
+
+	if len(c.Fasthttp.Response.Body()) == 0 {
+		c.Status(httpErr.StatusCode())
+	}
+	c.Set(fiber.HeaderContentType, MIMETextEventStream)
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Fasthttp.Response.AppendBodyString("event: error\ndata: " + string(data) + "\n\n")
+}
+
+// Map an HTTP status code to a WebSocket close code
+func webSocketCloseCode(status int) int {
+	if status == fiber.StatusServiceUnavailable || status == fiber.StatusTooManyRequests {
+		return WebSocketCloseTryAgainLater
+	} else if status >= 500 {
+		return WebSocketCloseInternalError
+	}
+	return WebSocketClosePolicyViolation
+}
+
+// Accept the WebSocket handshake and close the connection right away,
+// with the close code mapped from the status code and the message as reason.
+func handleWebSocket(c *fiber.Ctx, cfg Config, args ...interface{}) {
+	httpErr := getHTTPError(args...)
+
+	reason := httpErr.Message()
+	if len(reason) > maxCloseReason {
+		reason = reason[:maxCloseReason]
+		for !utf8.ValidString(reason) {
+			reason = reason[:len(reason)-1]
+		}
+	}
+	frame := make([]byte, 4, 4+len(reason))
+	frame[0] = 0x88 // FIN + close opcode
+	frame[1] = byte(2 + len(reason))
+	binary.BigEndian.PutUint16(frame[2:], uint16(webSocketCloseCode(httpErr.StatusCode())))
+	frame = append(frame, reason...)
+
+	accept := sha1.Sum([]byte(c.Get(fiber.HeaderSecWebSocketKey) + webSocketGUID))
+	c.Fasthttp.Response.ResetBody()
+	c.Status(fiber.StatusSwitchingProtocols)
+	c.Set(fiber.HeaderUpgrade, "websocket")
+	c.Set(fiber.HeaderConnection, "Upgrade")
+	c.Set(fiber.HeaderSecWebSocketAccept, base64.StdEncoding.EncodeToString(accept[:]))
+	c.Fasthttp.Hijack(func(conn net.Conn) {
+		conn.Write(frame)
+	})
+}
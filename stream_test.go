@@ -0,0 +1,113 @@
+package fiber_errhandler
+
+import (
+	"bufio"
+	"encoding/binary"
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestErrHandler_sse(t *testing.T) {
+	var observed int
+	app := fiber.New()
+	app.Use(New(Config{
+		Reset: true,
+		Observers: []Observer{observerFunc(func(c *fiber.Ctx, e *Event) {
+			observed = e.StatusCode
+		})},
+	}))
+	app.Get("/events", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusNotFound, "Stream not found", nil))
+	})
+	app.Get("/events/1", func(c *fiber.Ctx) {
+		c.Set(fiber.HeaderContentType, MIMETextEventStream)
+		c.Write("data: first\n\n")
+		c.Next(NewHttpError(fiber.StatusInternalServerError, "Lost upstream", nil).WithCode("UPSTREAM"))
+	})
+
+	for _, tc := range []struct {
+		target   string
+		status   int
+		observed int
+		expected string
+	}{
+		{"/events", fiber.StatusNotFound, fiber.StatusNotFound,
+			"event: error\ndata: {\"message\":\"Stream not found\"}\n\n"},
+		{"/events/1", fiber.StatusOK, fiber.StatusInternalServerError,
+			"data: first\n\nevent: error\ndata: {\"code\":\"UPSTREAM\",\"message\":\"Lost upstream\"}\n\n"},
+	} {
+		req := httptest.NewRequest("GET", tc.target, nil)
+		req.Header.Set("Accept", MIMETextEventStream)
+		if resp, err := app.Test(req); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, tc.status, resp.StatusCode)
+			assert.Equal(t, MIMETextEventStream, resp.Header.Get("Content-Type"))
+			body, _ := ioutil.ReadAll(resp.Body)
+			assert.Equal(t, tc.expected, string(body))
+			assert.Equal(t, tc.observed, observed)
+		}
+	}
+}
+
+func TestErrHandler_websocket(t *testing.T) {
+	observed := make(chan int, 1)
+	app := fiber.New()
+	app.Settings.DisableStartupMessage = true
+	app.Use(New(Config{
+		Observers: []Observer{observerFunc(func(c *fiber.Ctx, e *Event) {
+			observed <- e.StatusCode
+		})},
+	}))
+	app.Get("/ws", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusForbidden, "Not allowed", nil))
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go app.Serve(ln)
+	defer app.Shutdown()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	assert.NoError(t, err)
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Second))
+
+	req, _ := http.NewRequest("GET", "http://"+ln.Addr().String()+"/ws", nil)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	assert.NoError(t, req.Write(conn))
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, req)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, fiber.StatusSwitchingProtocols, resp.StatusCode)
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+
+	frame := make([]byte, 4+len("Not allowed"))
+	_, err = io.ReadFull(r, frame)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(0x88), frame[0])
+	assert.Equal(t, byte(2+len("Not allowed")), frame[1])
+	assert.Equal(t, uint16(WebSocketClosePolicyViolation), binary.BigEndian.Uint16(frame[2:4]))
+	assert.Equal(t, "Not allowed", string(frame[4:]))
+	assert.Equal(t, fiber.StatusForbidden, <-observed)
+}
+
+func TestWebSocketCloseCode(t *testing.T) {
+	assert.Equal(t, WebSocketClosePolicyViolation, webSocketCloseCode(fiber.StatusUnauthorized))
+	assert.Equal(t, WebSocketCloseInternalError, webSocketCloseCode(fiber.StatusInternalServerError))
+	assert.Equal(t, WebSocketCloseTryAgainLater, webSocketCloseCode(fiber.StatusServiceUnavailable))
+	assert.Equal(t, WebSocketCloseTryAgainLater, webSocketCloseCode(fiber.StatusTooManyRequests))
+}
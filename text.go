@@ -0,0 +1,107 @@
+package fiber_errhandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber"
+	"net/http"
+	"sort"
+	"text/template"
+)
+
+// DefaultTextTemplate renders e.g. `404 Not Found: user not found (code USER_NOT_FOUND, request abc123)`
+// followed by the data as `key=value` lines when `Config.TextData` is enabled.
+var DefaultTextTemplate = template.Must(template.New("error").Parse(
+	`{{.Status}} {{.StatusText}}: {{.Message}}` +
+		`{{if or .Code .RequestID}} ({{if .Code}}code {{.Code}}{{if .RequestID}}, {{end}}{{end}}` +
+		`{{if .RequestID}}request {{.RequestID}}{{end}}){{end}}` + "\n" +
+		`{{range .Data}}{{.Key}}={{.Value}}` + "\n" + `{{end}}`))
+
+// TextError is the data passed to `Config.TextTemplate`
+type TextError struct {
+	Status     int
+	StatusText string
+	Message    string
+	Code       string
+	RequestID  string
+	TraceID    string
+	Data       []TextField
+}
+
+// TextField is a `key=value` line of the error data
+type TextField struct {
+	Key   string
+	Value string
+}
+
+// Flatten the error data to `key=value` lines sorted by key.
+// Field errors use the field as key, other data is flattened through its JSON representation.
+func textFields(data interface{}) []TextField {
+	if data == nil {
+		return nil
+	}
+	var fields []TextField
+	if fe, ok := data.([]FieldError); ok {
+		for _, f := range fe {
+			fields = append(fields, TextField{f.Field, f.Message})
+		}
+		return fields
+	}
+
+	var v interface{}
+	if b, err := json.Marshal(data); err != nil || json.Unmarshal(b, &v) != nil {
+		return []TextField{{"error", fmt.Sprint(data)}}
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return []TextField{{"error", textValue(v)}}
+	}
+	for k, val := range m {
+		fields = append(fields, TextField{k, textValue(val)})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+	return fields
+}
+
+// Strings are written as is, other values as JSON
+func textValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// Send error message as plain text rendered with `cfg.TextTemplate`
+func handleTextTemplate(c *fiber.Ctx, cfg Config, args ...interface{}) {
+	httpErr := getHTTPError(args...)
+
+	te := TextError{
+		Status:     httpErr.StatusCode(),
+		StatusText: http.StatusText(httpErr.StatusCode()),
+		Message:    httpErr.Message(),
+		Code:       errorCode(httpErr),
+		RequestID:  requestID(c),
+		TraceID:    getTraceID(c, cfg),
+	}
+	// Only HTTPError data is rendered, getHTTPError sets the message of plain errors as data
+	if cfg.TextData && len(args) > 0 {
+		if _, ok := args[0].(HTTPError); ok {
+			data := httpErr.Data()
+			var fe FieldErrorer
+			if errors.As(httpErr, &fe) {
+				data = fe.FieldErrors()
+			}
+			te.Data = textFields(data)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := cfg.TextTemplate.Execute(&buf, te); err != nil {
+		c.Status(httpErr.StatusCode()).SendString(httpErr.Message())
+		return
+	}
+	c.Status(httpErr.StatusCode()).SendBytes(buf.Bytes())
+}
@@ -0,0 +1,76 @@
+package fiber_errhandler
+
+import (
+	"errors"
+	"github.com/gofiber/fiber"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"text/template"
+)
+
+func TestErrHandler_text_template(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(Config{
+		TextTemplate: DefaultTextTemplate,
+		TextData:     true,
+	}))
+	app.Get("/users/1", func(c *fiber.Ctx) {
+		c.Set(fiber.HeaderXRequestID, "abc123")
+		c.Next(NewHttpError(fiber.StatusNotFound, "user not found", nil).WithCode("USER_NOT_FOUND"))
+	})
+	app.Get("/users/2", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusConflict, "user exists", fiber.Map{"id": 2, "name": "john"}))
+	})
+	app.Post("/users", func(c *fiber.Ctx) {
+		c.Next(NewValidationError("invalid user", FieldError{Field: "email", Message: "is required"}).WithCode("INVALID"))
+	})
+	app.Get("/panic", func(c *fiber.Ctx) {
+		panic(errors.New("boom"))
+	})
+
+	for _, tc := range []struct {
+		method   string
+		target   string
+		status   int
+		expected string
+	}{
+		{"GET", "/users/1", fiber.StatusNotFound,
+			"404 Not Found: user not found (code USER_NOT_FOUND, request abc123)\n"},
+		{"GET", "/users/2", fiber.StatusConflict,
+			"409 Conflict: user exists\nid=2\nname=john\n"},
+		{"POST", "/users", fiber.StatusUnprocessableEntity,
+			"422 Unprocessable Entity: invalid user (code INVALID)\nemail=is required\n"},
+		{"GET", "/panic", fiber.StatusInternalServerError,
+			"500 Internal Server Error: boom\n"},
+	} {
+		req := httptest.NewRequest(tc.method, tc.target, nil)
+		if resp, err := app.Test(req); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, tc.status, resp.StatusCode)
+			body, _ := ioutil.ReadAll(resp.Body)
+			assert.Equal(t, tc.expected, string(body))
+		}
+	}
+}
+
+func TestErrHandler_text_custom_template(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(Config{
+		TextTemplate: template.Must(template.New("error").Parse(`error: {{.Message}} [{{.Status}}]`)),
+	}))
+	app.Get("/", func(c *fiber.Ctx) {
+		c.Next(NewHttpError(fiber.StatusBadRequest, "bad input", fiber.Map{"ignored": true}))
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		body, _ := ioutil.ReadAll(resp.Body)
+		assert.Equal(t, "error: bad input [400]", string(body))
+	}
+}
@@ -0,0 +1,42 @@
+package fiber_errhandler
+
+import (
+	"errors"
+	"github.com/gofiber/fiber"
+)
+
+// Transformer receives the error raised by the handler and returns the error to continue with.
+// It may enrich, remap, redact or replace the error before it is rendered.
+// Returning nil keeps the error unchanged.
+type Transformer func(*fiber.Ctx, error) error
+
+// Chain composes multiple transformers into one, applied in the given order.
+func Chain(transformers ...Transformer) Transformer {
+	return func(c *fiber.Ctx, err error) error {
+		return transform(c, err, transformers)
+	}
+}
+
+// MapError returns a transformer which replaces any error matching `target` (see errors.Is)
+// with `to`, e.g. to convert `sql.ErrNoRows` into a 404.
+func MapError(target error, to HTTPError) Transformer {
+	return func(c *fiber.Ctx, err error) error {
+		if errors.Is(err, target) {
+			return to
+		}
+		return err
+	}
+}
+
+// Apply transformers in order, a transformer returning nil keeps the current error
+func transform(c *fiber.Ctx, err error, transformers []Transformer) error {
+	for _, t := range transformers {
+		if t == nil {
+			continue
+		}
+		if e := t(c, err); e != nil {
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+package fiber_errhandler
+
+import (
+	"github.com/gofiber/fiber"
+	"strings"
+)
+
+// FieldError describes an invalid field of the request
+type FieldError struct {
+	// Field name, nested fields are separated by a dot, e.g. `address.city`.
+	// A JSON pointer (starting with `/`) is used as is by JSON:API.
+	Field string `json:"field"`
+	// Message describing why the field is invalid
+	Message string `json:"message"`
+	// Application specific error code
+	Code string `json:"code,omitempty"`
+	// Query is true if the field is a query parameter
+	Query bool `json:"query,omitempty"`
+}
+
+// FieldErrorer is implemented by errors carrying invalid fields, e.g. ValidationError
+type FieldErrorer interface {
+	FieldErrors() []FieldError
+}
+
+// ValidationError is an HTTPError listing the invalid fields of the request
+type ValidationError struct {
+	*httpError
+	fields []FieldError
+}
+
+// NewValidationError returns a 422 error with the invalid fields as data
+func NewValidationError(message string, fields ...FieldError) *ValidationError {
+	return &ValidationError{
+		httpError: NewHttpError(fiber.StatusUnprocessableEntity, message, fields),
+		fields:    fields,
+	}
+}
+
+func (ve *ValidationError) FieldErrors() []FieldError {
+	return ve.fields
+}
+
+// JSON pointer of the field in a JSON:API document
+func (fe FieldError) pointer() string {
+	if strings.HasPrefix(fe.Field, "/") {
+		return fe.Field
+	}
+	return "/data/attributes/" + strings.ReplaceAll(fe.Field, ".", "/")
+}
+
+// WithCode sets the application specific error code
+func (ve *ValidationError) WithCode(code string) *ValidationError {
+	ve.httpError.WithCode(code)
+	return ve
+}
+
+// WithMessageKey sets the key and arguments used to resolve a localised message from Config.Catalog
+func (ve *ValidationError) WithMessageKey(key string, args map[string]interface{}) *ValidationError {
+	ve.httpError.WithMessageKey(key, args)
+	return ve
+}
+
+// Copy of the error with another message
+func (ve *ValidationError) withMessage(message string) HTTPError {
+	cp := *ve.httpError
+	cp.message = message
+	return &ValidationError{httpError: &cp, fields: ve.fields}
+}
@@ -184,3 +184,90 @@ func TestErrHandler_view_template(t *testing.T) {
 		}
 	}
 }
+
+func TestErrHandler_view_override(t *testing.T) {
+	app := fiber.New()
+	app.Settings.Templates = html.New("./views", ".html")
+	app.Use(errhandler.New(errhandler.Config{
+		UseTemplate: true,
+	}))
+
+	api := app.Group("/api", errhandler.Override(func(cfg *errhandler.Config) {
+		cfg.ContentType = fiber.MIMEApplicationJSON
+	}))
+	api.Get("/400", func(c *fiber.Ctx) {
+		c.Next(errhandler.NewHttpError(fiber.StatusBadRequest, "Bad request", fiber.Map{
+			"Field": "Not empty",
+		}))
+	})
+	app.Get("/400", func(c *fiber.Ctx) {
+		c.Next(errhandler.NewHttpError(fiber.StatusBadRequest, "Bad request", fiber.Map{
+			"Field": "Not empty",
+		}))
+	})
+
+	req := httptest.NewRequest("GET", "/api/400", nil)
+	req.Header.Set("Accept", browserAccept)
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		b := make(map[string]interface{})
+		if err := json.NewDecoder(resp.Body).Decode(&b); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, map[string]interface{}{
+				"message": "Bad request",
+				"error": map[string]interface{}{
+					"Field": "Not empty",
+				},
+			}, b)
+		}
+	}
+
+	req = httptest.NewRequest("GET", "/400", nil)
+	req.Header.Set("Accept", browserAccept)
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		if b, err := ioutil.ReadAll(resp.Body); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, "Bad request<br />Not empty", string(b))
+		}
+	}
+}
+
+func TestErrHandler_view_localized(t *testing.T) {
+	catalog := errhandler.NewCatalog("en")
+	assert.NoError(t, catalog.Add("en", map[string]interface{}{"bad_request": "Bad request"}))
+	assert.NoError(t, catalog.Add("fr", map[string]interface{}{"bad_request": "Requête invalide"}))
+
+	app := fiber.New()
+	app.Settings.Templates = html.New("./views", ".html")
+	app.Use(errhandler.New(errhandler.Config{
+		UseTemplate: true,
+		Catalog:     catalog,
+		Handler: func(c *fiber.Ctx, err error, f func(...interface{})) {
+			f("localized", err)
+		},
+	}))
+	app.Get("/400", func(c *fiber.Ctx) {
+		c.Next(errhandler.NewHttpError(fiber.StatusBadRequest, "Bad request", nil).WithCode("BAD_REQUEST").WithMessageKey("bad_request", nil))
+	})
+
+	req := httptest.NewRequest("GET", "/400", nil)
+	req.Header.Set("Accept", browserAccept)
+	req.Header.Set("Accept-Language", "fr-FR,fr;q=0.9")
+	if resp, err := app.Test(req); err != nil {
+		assert.NoError(t, err)
+	} else {
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		if b, err := ioutil.ReadAll(resp.Body); err != nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, "fr<br />BAD_REQUEST: Requête invalide", string(b))
+		}
+	}
+}